@@ -0,0 +1,57 @@
+// Package arena runs two bots against each other in one process without network (see bot.Bot).
+// The world is updated as fast as possible and the bots are called once per iteration,
+// so matches are deterministic for a given seed and can be used in unit tests.
+package arena
+
+import (
+	"github.com/SchnorcherSepp/TankWars/bot"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"github.com/SchnorcherSepp/TankWars/maps"
+	"github.com/SchnorcherSepp/TankWars/remote"
+)
+
+// Run plays a match on the map ('field', 'fortress', 'random' or 'test') until one player has lost all units
+// or maxIterations is reached. The red bot is called before the blue bot.
+// The seed initializes the random map and the random number generator of the world (spawn points, macros), so
+// matches can also run in parallel.
+func Run(red, blue bot.Bot, mapName string, seed int64, maxIterations uint64) (Result, error) {
+	resources.MuteSound = true
+
+	// create world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	w.SetSeed(seed)
+	if err := maps.LoadSeed(w, mapName, seed); err != nil {
+		return Result{}, err
+	}
+
+	// players
+	sc := remote.NewScripts()
+	runners := []*bot.Runner{
+		bot.NewRunner(client(w, sc, core.RedTank), red),
+		bot.NewRunner(client(w, sc, core.BlueTank), blue),
+	}
+
+	// run match
+	for {
+		for _, r := range runners {
+			if _, err := r.Step(); err != nil {
+				return Result{}, err
+			}
+		}
+		if runners[0].Over() || w.Iteration() >= maxIterations {
+			return newResult(w), nil
+		}
+		w.Update()
+	}
+}
+
+//---------------- CONNECTION ----------------------------------------------------------------------------------------//
+
+// client returns a local client that sends the commands of a bot directly to the command handlers of the server
+// (see remote.Execute). Both clients of a match share the user-defined macros.
+func client(w *core.World, sc *remote.Scripts, owner string) *remote.TcpClient {
+	return remote.NewLocalClient(func(cmd string) string {
+		return remote.Execute(w, sc, owner, cmd)
+	})
+}
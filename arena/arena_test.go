@@ -0,0 +1,90 @@
+package arena
+
+import (
+	"github.com/SchnorcherSepp/TankWars/bot"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/remote"
+	"reflect"
+	"testing"
+)
+
+// attackBot buys tanks and attacks with all units.
+type attackBot struct {
+	bot.Base
+	ticks int
+}
+
+func (b *attackBot) OnTick(w *remote.JsonWorld) {
+	b.ticks++
+	if b.ticks%100 == 1 {
+		_, _ = b.BuyTank(5, 70, core.WeaponCannon)
+	}
+	for _, t := range w.Tanks {
+		if t.Owner == b.Me() && t.Macro != core.MacroAttackMove {
+			_ = b.SetMacro(t.ID, core.MacroAttackMove)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	a, idle := new(attackBot), new(bot.Base)
+	res, err := Run(a, idle, "test", 42, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Winner != core.RedTank || res.Timeout || res.UnitsBlue != 0 || res.UnitsRed == 0 || len(res.Units) < res.UnitsRed {
+		t.Errorf("wrong value %+v", res)
+	}
+	if a.ticks != int(res.Iterations) || idle.Me() != core.BlueTank {
+		t.Error("wrong value", a.ticks, res.Iterations)
+	}
+
+	// same seed, same result
+	res2, _ := Run(new(attackBot), new(bot.Base), "test", 42, 20000)
+	res.Units, res2.Units = nil, nil // new tank ids
+	if !reflect.DeepEqual(res, res2) {
+		t.Errorf("not deterministic %+v\n%+v", res, res2)
+	}
+}
+
+func TestRun_Parallel(t *testing.T) {
+	want, err := Run(new(attackBot), new(bot.Base), "test", 7, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the matches don't share a random number generator (spawn points)
+	results := make(chan Result, 3)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			res, _ := Run(new(attackBot), new(bot.Base), "test", 7, 600)
+			results <- res
+		}()
+	}
+	for i := 0; i < cap(results); i++ {
+		if res := <-results; !reflect.DeepEqual(positions(want), positions(res)) {
+			t.Errorf("not deterministic %+v\n%+v", positions(want), positions(res))
+		}
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	res, err := Run(new(bot.Base), new(bot.Base), "fortress", 1, 100)
+	if err != nil || !res.Timeout || res.Winner != "" || res.Iterations != 100 {
+		t.Errorf("wrong value %+v %v", res, err)
+	}
+
+	// errors
+	if _, err := Run(new(bot.Base), new(bot.Base), "nothing", 1, 100); err == nil || err.Error() != "unknown map" {
+		t.Error("wrong value", err)
+	}
+}
+
+// positions returns the positions of all remaining units (the tank ids are new in every match).
+func positions(res Result) []remote.JsonPosition {
+	list := make([]remote.JsonPosition, len(res.Units))
+	for i, u := range res.Units {
+		list[i] = u.Pos
+	}
+	return list
+}
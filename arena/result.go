@@ -0,0 +1,34 @@
+package arena
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/remote"
+)
+
+// Result holds all data of a finished match.
+type Result struct {
+	Winner     string            // core.RedTank, core.BlueTank or "" (draw or timeout)
+	Iterations uint64            // played iterations
+	Timeout    bool              // the match was stopped by maxIterations
+	UnitsRed   int               // remaining tanks and buildings of red
+	UnitsBlue  int               // remaining tanks and buildings of blue
+	Units      []remote.JsonTank // all remaining units (incl. neutral objects)
+}
+
+// newResult returns the result of the world.
+func newResult(w *core.World) Result {
+	red, blue := w.UnitCount()
+	r := Result{
+		Iterations: w.Iteration(),
+		Timeout:    red > 0 && blue > 0,
+		UnitsRed:   red,
+		UnitsBlue:  blue,
+		Units:      remote.NewJsonWorld(w).Tanks,
+	}
+	if red > 0 && blue == 0 {
+		r.Winner = core.RedTank
+	} else if blue > 0 && red == 0 {
+		r.Winner = core.BlueTank
+	}
+	return r
+}
@@ -0,0 +1,3 @@
+// Package behavior is a small behavior tree runtime for server-side unit AI.
+// A tree is evaluated with every update and can be loaded from JSON (see Parse).
+package behavior
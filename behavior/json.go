@@ -0,0 +1,103 @@
+package behavior
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// limits of a tree loaded from JSON
+const (
+	MaxNodes = 256 // max. number of nodes
+	MaxDepth = 32  // max. depth of the tree
+)
+
+// Spec is the JSON representation of a node.
+//
+//	{"type":"selector","children":[
+//	  {"type":"sequence","children":[
+//	    {"type":"condition","name":"HealthBelow","args":[30]},
+//	    {"type":"action","name":"MoveTo","args":[100,200]}]},
+//	  {"type":"action","name":"GuardMode"}]}
+type Spec struct {
+	Type     string `json:"type"`               // sequence, selector, inverter, succeeder, cooldown, condition or action
+	Name     string `json:"name,omitempty"`     // name of the condition or action
+	Args     []int  `json:"args,omitempty"`     // int arguments of the condition or action
+	Value    string `json:"value,omitempty"`    // string argument of the condition (see Status)
+	Ticks    int    `json:"ticks,omitempty"`    // iterations of the cooldown
+	Child    *Spec  `json:"child,omitempty"`    // child of a decorator
+	Children []Spec `json:"children,omitempty"` // children of a sequence or selector
+}
+
+// Parse loads a tree from JSON.
+// The tree is limited by MaxNodes and MaxDepth.
+func Parse(data []byte) (*Tree, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	nodes := 0
+	root, err := build(&spec, 1, &nodes)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{Root: root}, nil
+}
+
+// build converts a spec recursively into nodes.
+func build(s *Spec, depth int, nodes *int) (Node, error) {
+	// check limits
+	*nodes++
+	if *nodes > MaxNodes {
+		return nil, fmt.Errorf("too many nodes (max. %d)", MaxNodes)
+	}
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("tree too deep (max. %d)", MaxDepth)
+	}
+
+	switch s.Type {
+	case "sequence", "selector":
+		children := make([]Node, 0, len(s.Children))
+		for i := range s.Children {
+			c, err := build(&s.Children[i], depth+1, nodes)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, c)
+		}
+		if s.Type == "sequence" {
+			return &Sequence{Children: children}, nil
+		}
+		return &Selector{Children: children}, nil
+
+	case "inverter", "succeeder", "cooldown":
+		if s.Child == nil {
+			return nil, fmt.Errorf("'%s' needs a child", s.Type)
+		}
+		c, err := build(s.Child, depth+1, nodes)
+		if err != nil {
+			return nil, err
+		}
+		switch s.Type {
+		case "inverter":
+			return &Inverter{Child: c}, nil
+		case "succeeder":
+			return &Succeeder{Child: c}, nil
+		default:
+			if s.Ticks < 0 {
+				return nil, errors.New("'cooldown' needs positive ticks")
+			}
+			return &Cooldown{Child: c, Ticks: uint64(s.Ticks)}, nil
+		}
+
+	case "condition":
+		return newCondition(s.Name, s.Value, s.Args)
+
+	case "action":
+		return newAction(s.Name, s.Args)
+
+	default:
+		return nil, fmt.Errorf("unknown node type '%s'", s.Type)
+	}
+}
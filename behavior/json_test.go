@@ -0,0 +1,113 @@
+package behavior
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(red)
+
+	// retreat if damaged, attack if possible, otherwise move
+	tr, err := Parse([]byte(`{"type":"selector","children":[
+		{"type":"sequence","children":[
+			{"type":"condition","name":"HealthBelow","args":[30]},
+			{"type":"action","name":"MoveTo","args":[100,300]}]},
+		{"type":"sequence","children":[
+			{"type":"condition","name":"HasTarget"},
+			{"type":"condition","name":"Ready"},
+			{"type":"action","name":"FireAtTarget"}]},
+		{"type":"action","name":"MoveTo","args":[700,300]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	red.SetMacro(tr.Update)
+
+	// move
+	w.UpdateN(400)
+	if red.Pos().X < 650 {
+		t.Error("wrong value", red.Pos().X)
+	}
+
+	// attack
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponNone)
+	blue.SetPosition(core.NewPosition(red.Pos().X, red.Pos().Y+200), core.East)
+	w.AddTank(blue)
+	w.UpdateN(600)
+	if blue.Health() == 100 {
+		t.Error("wrong value", blue.Health())
+	}
+
+	// retreat
+	blue.Remove()
+	red.Hit(80)
+	w.UpdateN(600)
+	if red.Pos().X > 150 {
+		t.Error("wrong value", red.Pos().X)
+	}
+}
+
+func TestParse_Nodes(t *testing.T) {
+	// all nodes
+	for _, j := range []string{
+		`{"type":"inverter","child":{"type":"condition","name":"Moving"}}`,
+		`{"type":"succeeder","child":{"type":"condition","name":"Blocked"}}`,
+		`{"type":"cooldown","ticks":30,"child":{"type":"action","name":"Stop"}}`,
+		`{"type":"condition","name":"Status","value":"Reloading"}`,
+		`{"type":"condition","name":"HealthAbove","args":[50]}`,
+		`{"type":"condition","name":"Near","args":[1,2,3]}`,
+		`{"type":"action","name":"FireAt","args":[1,2]}`,
+		`{"type":"action","name":"Forward"}`,
+		`{"type":"action","name":"Backward"}`,
+		`{"type":"action","name":"Left"}`,
+		`{"type":"action","name":"Right"}`,
+		`{"type":"action","name":"GuardMode"}`,
+		`{"type":"action","name":"AttackMove"}`,
+		`{"type":"action","name":"Kite"}`,
+	} {
+		tr, err := Parse([]byte(j))
+		if err != nil {
+			t.Error(j, err)
+			continue
+		}
+		w := core.NewWorld(100, 100)
+		nt, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+		w.AddTank(nt)
+		tr.Update(nt)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for j, e := range map[string]string{
+		`{`:                   "unexpected end of JSON input",
+		`{"type":"nothing"}`:  "unknown node type 'nothing'",
+		`{"type":"inverter"}`: "'inverter' needs a child",
+		`{"type":"cooldown","ticks":-1,"child":{"type":"action","name":"Stop"}}`: "'cooldown' needs positive ticks",
+		`{"type":"condition","name":"nothing"}`:                                  "unknown condition 'nothing'",
+		`{"type":"action","name":"nothing"}`:                                     "unknown action 'nothing'",
+		`{"type":"action","name":"MoveTo"}`:                                      "'MoveTo' expects 2 args but got 0",
+		`{"type":"sequence","children":[{"type":"nothing"}]}`:                    "unknown node type 'nothing'",
+	} {
+		if _, err := Parse([]byte(j)); err == nil || err.Error() != e {
+			t.Error(j, err)
+		}
+	}
+
+	// limits
+	deep := strings.Repeat(`{"type":"inverter","child":`, MaxDepth) + `{"type":"action","name":"Stop"}` + strings.Repeat(`}`, MaxDepth)
+	if _, err := Parse([]byte(deep)); err == nil || err.Error() != "tree too deep (max. 32)" {
+		t.Error(err)
+	}
+	wide := `{"type":"sequence","children":[` + strings.Repeat(`{"type":"action","name":"Stop"},`, MaxNodes) + `{"type":"action","name":"Stop"}]}`
+	if _, err := Parse([]byte(wide)); err == nil || err.Error() != "too many nodes (max. 256)" {
+		t.Error(err)
+	}
+}
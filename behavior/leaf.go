@@ -0,0 +1,164 @@
+package behavior
+
+import (
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/macro"
+)
+
+// newCondition returns the condition with the given name.
+// The conditions are bound to the core functions of the tank.
+func newCondition(name, value string, args []int) (Node, error) {
+	var check func(t *core.Tank) bool
+
+	switch name {
+	case "HasTarget": // any enemy in weapon range (see core.PossibleTargets)
+		check = func(t *core.Tank) bool {
+			return len(core.PossibleTargets(t, macro.Filters(t)...)) > 0
+		}
+	case "Ready": // ready to fire (see core.Tank.Status)
+		check = func(t *core.Tank) bool {
+			rdy, _ := t.Status()
+			return rdy
+		}
+	case "Status": // weapon status equals value (see core.StatusReloading, ...)
+		check = func(t *core.Tank) bool {
+			_, status := t.Status()
+			return status == value
+		}
+	case "HealthBelow": // health < args[0]
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		check = func(t *core.Tank) bool {
+			return t.Health() < args[0]
+		}
+	case "HealthAbove": // health > args[0]
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+		check = func(t *core.Tank) bool {
+			return t.Health() > args[0]
+		}
+	case "Near": // distance to x=args[0], y=args[1] <= args[2]
+		if err := checkArgs(name, args, 3); err != nil {
+			return nil, err
+		}
+		pos := core.NewPosition(args[0], args[1])
+		check = func(t *core.Tank) bool {
+			return core.Distance(t.Pos(), pos) <= float64(args[2])
+		}
+	case "Moving":
+		check = func(t *core.Tank) bool {
+			return t.Moving()
+		}
+	case "Blocked":
+		check = func(t *core.Tank) bool {
+			return t.Blocked()
+		}
+	default:
+		return nil, fmt.Errorf("unknown condition '%s'", name)
+	}
+
+	return &Condition{Name: name, Check: check}, nil
+}
+
+// newAction returns the action with the given name.
+// The actions are bound to the core functions of the tank and the macros.
+func newAction(name string, args []int) (Node, error) {
+	var run func(t *core.Tank) Status
+
+	switch name {
+	case "FireAtTarget": // attack the target selected by the strategy of the tank (see macro.SelectTarget)
+		run = func(t *core.Tank) Status {
+			target, ok := macro.SelectTarget(t, core.PossibleTargets(t, macro.Filters(t)...))
+			if !ok {
+				return Failure
+			}
+			macro.Attack(t, target)
+			return Success
+		}
+	case "FireAt": // fire at x=args[0], y=args[1]
+		if err := checkArgs(name, args, 2); err != nil {
+			return nil, err
+		}
+		pos := core.NewPosition(args[0], args[1])
+		run = func(t *core.Tank) Status {
+			if ok, _ := t.FireAt(pos); ok {
+				return Success
+			}
+			return Failure
+		}
+	case core.MacroMoveTo: // move to x=args[0], y=args[1]
+		if err := checkArgs(name, args, 2); err != nil {
+			return nil, err
+		}
+		pos := core.NewPosition(args[0], args[1])
+		run = func(t *core.Tank) Status {
+			return fromOrderState(macro.MoveTo(t, pos))
+		}
+	case "Forward":
+		run = func(t *core.Tank) Status {
+			t.Forward()
+			return Success
+		}
+	case "Backward":
+		run = func(t *core.Tank) Status {
+			t.Backward()
+			return Success
+		}
+	case "Stop":
+		run = func(t *core.Tank) Status {
+			t.Stop()
+			return Success
+		}
+	case "Left":
+		run = func(t *core.Tank) Status {
+			t.Left()
+			return Success
+		}
+	case "Right":
+		run = func(t *core.Tank) Status {
+			t.Right()
+			return Success
+		}
+	case core.MacroGuardMode:
+		run = func(t *core.Tank) Status {
+			return fromOrderState(macro.GuardMode(t, macro.Filters(t)...))
+		}
+	case core.MacroAttackMove:
+		run = func(t *core.Tank) Status {
+			macro.AttackMove(t, macro.Filters(t)...)
+			return Running
+		}
+	case core.MacroKite:
+		run = func(t *core.Tank) Status {
+			macro.Kite(t, macro.Filters(t)...)
+			return Running
+		}
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", name)
+	}
+
+	return &Action{Name: name, Run: run}, nil
+}
+
+// checkArgs returns an error if the number of arguments is wrong.
+func checkArgs(name string, args []int, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("'%s' expects %d args but got %d", name, n, len(args))
+	}
+	return nil
+}
+
+// fromOrderState converts the result of a macro to a node status.
+func fromOrderState(s core.OrderState) Status {
+	switch s {
+	case core.OrderDone:
+		return Success
+	case core.OrderFailed:
+		return Failure
+	default:
+		return Running
+	}
+}
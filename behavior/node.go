@@ -0,0 +1,168 @@
+package behavior
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// Status is returned by every node with each tick.
+type Status int
+
+// node states
+const (
+	Success Status = iota // the node has succeeded
+	Failure               // the node has failed
+	Running               // the node needs more ticks
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case Success:
+		return "success"
+	case Failure:
+		return "failure"
+	case Running:
+		return "running"
+	default:
+		return "unknown"
+	}
+}
+
+// Node is a part of a behavior tree.
+type Node interface {
+	Tick(t *core.Tank) Status
+}
+
+// Tree is a behavior tree that is evaluated from the root with every update.
+// The tree is reactive: a higher priority branch can interrupt a running node.
+type Tree struct {
+	Root Node
+}
+
+// Update is the macro function (see core.Tank.SetMacro).
+func (tr *Tree) Update(t *core.Tank) {
+	if tr == nil || tr.Root == nil || t == nil {
+		return // EXIT
+	}
+	tr.Root.Tick(t)
+}
+
+//---------------- COMPOSITE -----------------------------------------------------------------------------------------//
+
+// Sequence ticks its children in order until one of them does not succeed.
+// It succeeds if all children succeed.
+type Sequence struct {
+	Children []Node
+}
+
+// Tick implements Node.
+func (n *Sequence) Tick(t *core.Tank) Status {
+	for _, c := range n.Children {
+		if s := c.Tick(t); s != Success {
+			return s
+		}
+	}
+	return Success
+}
+
+// Selector ticks its children in order until one of them does not fail.
+// It fails if all children fail.
+type Selector struct {
+	Children []Node
+}
+
+// Tick implements Node.
+func (n *Selector) Tick(t *core.Tank) Status {
+	for _, c := range n.Children {
+		if s := c.Tick(t); s != Failure {
+			return s
+		}
+	}
+	return Failure
+}
+
+//---------------- DECORATOR -----------------------------------------------------------------------------------------//
+
+// Inverter turns Success into Failure and vice versa.
+type Inverter struct {
+	Child Node
+}
+
+// Tick implements Node.
+func (n *Inverter) Tick(t *core.Tank) Status {
+	switch s := n.Child.Tick(t); s {
+	case Success:
+		return Failure
+	case Failure:
+		return Success
+	default:
+		return s
+	}
+}
+
+// Succeeder always succeeds, unless the child is running.
+type Succeeder struct {
+	Child Node
+}
+
+// Tick implements Node.
+func (n *Succeeder) Tick(t *core.Tank) Status {
+	if n.Child.Tick(t) == Running {
+		return Running
+	}
+	return Success
+}
+
+// Cooldown fails for the given number of iterations after the child has succeeded.
+// The cooldown expires if the iteration goes back (see core.World.Reset).
+type Cooldown struct {
+	Child Node
+	Ticks uint64 // see core.GameSpeed
+	last  uint64 // iteration of the last success
+	used  bool   // the child has succeeded at least once
+}
+
+// Tick implements Node.
+func (n *Cooldown) Tick(t *core.Tank) Status {
+	var now uint64
+	if t.World() != nil {
+		now = t.World().Iteration()
+	}
+	if n.used && now >= n.last && now-n.last < n.Ticks {
+		return Failure // cooldown
+	}
+
+	s := n.Child.Tick(t)
+	if s == Success {
+		n.last = now
+		n.used = true
+	}
+	return s
+}
+
+//---------------- LEAF ----------------------------------------------------------------------------------------------//
+
+// Condition succeeds if the check returns true, otherwise it fails.
+type Condition struct {
+	Name  string
+	Check func(t *core.Tank) bool
+}
+
+// Tick implements Node.
+func (n *Condition) Tick(t *core.Tank) Status {
+	if n.Check(t) {
+		return Success
+	}
+	return Failure
+}
+
+// Action calls a function that changes the tank.
+type Action struct {
+	Name string
+	Run  func(t *core.Tank) Status
+}
+
+// Tick implements Node.
+func (n *Action) Tick(t *core.Tank) Status {
+	return n.Run(t)
+}
@@ -0,0 +1,128 @@
+package behavior
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"testing"
+)
+
+// fixed returns a node with a fixed status and counts the ticks.
+func fixed(s Status, ticks *int) Node {
+	return &Action{Name: "fixed", Run: func(t *core.Tank) Status {
+		*ticks++
+		return s
+	}}
+}
+
+func TestStatus_String(t *testing.T) {
+	if Success.String() != "success" || Failure.String() != "failure" || Running.String() != "running" || Status(9).String() != "unknown" {
+		t.Error("wrong value")
+	}
+}
+
+func TestSequence(t *testing.T) {
+	nt, _ := core.NewTank(nil, core.RedTank, 5, 15, core.WeaponCannon)
+	a, b, c := 0, 0, 0
+
+	n := &Sequence{Children: []Node{fixed(Success, &a), fixed(Running, &b), fixed(Success, &c)}}
+	if s := n.Tick(nt); s != Running || a != 1 || b != 1 || c != 0 {
+		t.Error("wrong value", s, a, b, c)
+	}
+	n = &Sequence{Children: []Node{fixed(Success, &a), fixed(Success, &c)}}
+	if s := n.Tick(nt); s != Success || a != 2 || c != 1 {
+		t.Error("wrong value", s, a, c)
+	}
+	n = &Sequence{Children: []Node{fixed(Failure, &a), fixed(Success, &c)}}
+	if s := n.Tick(nt); s != Failure || a != 3 || c != 1 {
+		t.Error("wrong value", s, a, c)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	nt, _ := core.NewTank(nil, core.RedTank, 5, 15, core.WeaponCannon)
+	a, b, c := 0, 0, 0
+
+	n := &Selector{Children: []Node{fixed(Failure, &a), fixed(Running, &b), fixed(Success, &c)}}
+	if s := n.Tick(nt); s != Running || a != 1 || b != 1 || c != 0 {
+		t.Error("wrong value", s, a, b, c)
+	}
+	n = &Selector{Children: []Node{fixed(Failure, &a), fixed(Failure, &c)}}
+	if s := n.Tick(nt); s != Failure || a != 2 || c != 1 {
+		t.Error("wrong value", s, a, c)
+	}
+	n = &Selector{Children: []Node{fixed(Success, &a), fixed(Success, &c)}}
+	if s := n.Tick(nt); s != Success || a != 3 || c != 1 {
+		t.Error("wrong value", s, a, c)
+	}
+}
+
+func TestDecorator(t *testing.T) {
+	w := core.NewWorld(100, 100)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon)
+	a := 0
+
+	// inverter
+	if s := (&Inverter{Child: fixed(Success, &a)}).Tick(nt); s != Failure {
+		t.Error("wrong value", s)
+	}
+	if s := (&Inverter{Child: fixed(Failure, &a)}).Tick(nt); s != Success {
+		t.Error("wrong value", s)
+	}
+	if s := (&Inverter{Child: fixed(Running, &a)}).Tick(nt); s != Running {
+		t.Error("wrong value", s)
+	}
+
+	// succeeder
+	if s := (&Succeeder{Child: fixed(Failure, &a)}).Tick(nt); s != Success {
+		t.Error("wrong value", s)
+	}
+	if s := (&Succeeder{Child: fixed(Running, &a)}).Tick(nt); s != Running {
+		t.Error("wrong value", s)
+	}
+
+	// cooldown
+	a = 0
+	n := &Cooldown{Child: fixed(Success, &a), Ticks: 10}
+	for i := 0; i < 25; i++ {
+		n.Tick(nt)
+		w.Update()
+	}
+	if a != 3 {
+		t.Error("wrong value", a)
+	}
+}
+
+func TestCooldown_Reset(t *testing.T) {
+	w := core.NewWorld(100, 100)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon)
+	a := 0
+
+	// success at iteration 20
+	n := &Cooldown{Child: fixed(Success, &a), Ticks: 10}
+	w.UpdateN(20)
+	n.Tick(nt)
+
+	// the iteration starts again with 0 (e.g. a new map)
+	w.Reset()
+	n.Tick(nt)
+	if a != 2 {
+		t.Error("wrong value", a)
+	}
+}
+
+func TestTree_Update(t *testing.T) {
+	nt, _ := core.NewTank(nil, core.RedTank, 5, 15, core.WeaponCannon)
+	a := 0
+
+	// test nil
+	var nilTree *Tree
+	nilTree.Update(nt)
+	(&Tree{}).Update(nt)
+
+	// update
+	tr := &Tree{Root: fixed(Success, &a)}
+	nt.SetMacro(tr.Update)
+	nt.Update()
+	if a != 1 {
+		t.Error("wrong value", a)
+	}
+}
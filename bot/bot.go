@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/remote"
+)
+
+// Bot is an AI that is driven by a Runner.
+// All callbacks are called in the same goroutine.
+type Bot interface {
+	// OnStart is called once before the first tick.
+	// The client can be used in all other callbacks.
+	OnStart(c *Client)
+	// OnTick is called once per new world iteration (see remote.JsonWorld.Iteration).
+	// Slow bots skip iterations.
+	OnTick(w *remote.JsonWorld)
+	// OnUnitDestroyed is called for every unit (tanks and bases of both players) that has disappeared since the last tick.
+	OnUnitDestroyed(t remote.JsonTank)
+	// OnGameOver is called once per game when a player has lost all units.
+	// In a series, the bot keeps running and the next game starts with new ticks (see Client.Me).
+	// The winner is core.RedTank, core.BlueTank or "" (draw).
+	OnGameOver(winner string)
+}
+
+// Base implements all callbacks of Bot without doing anything.
+// Embed it to implement only the callbacks you need; the client is set by OnStart.
+type Base struct {
+	*Client
+}
+
+// OnStart stores the client.
+func (b *Base) OnStart(c *Client) {
+	b.Client = c
+}
+
+// OnTick does nothing.
+func (b *Base) OnTick(*remote.JsonWorld) {}
+
+// OnUnitDestroyed does nothing.
+func (b *Base) OnUnitDestroyed(remote.JsonTank) {}
+
+// OnGameOver does nothing.
+func (b *Base) OnGameOver(string) {}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/remote"
+)
+
+// Client is the typed API of the server commands (see remote.TcpClient).
+// Server errors are returned as error (see remote.ParseError).
+type Client struct {
+	*remote.TcpClient
+	me string
+}
+
+// NewClient returns a new client using the connection.
+func NewClient(tc *remote.TcpClient) *Client {
+	me, _ := tc.MyName()
+	return &Client{
+		TcpClient: tc,
+		me:        me,
+	}
+}
+
+//---------------- GETTER --------------------------------------------------------------------------------------------//
+
+// Me returns the player of this connection (core.RedTank or core.BlueTank).
+// In a series, it is updated for every game, because the sides can be swapped (see Runner).
+func (c *Client) Me() string {
+	return c.me
+}
+
+// World returns the world status.
+func (c *Client) World() (*remote.JsonWorld, error) {
+	return c.GameStatus()
+}
+
+// Tank returns the status of a tank.
+func (c *Client) Tank(tankID string) (remote.JsonTank, error) {
+	return c.TankStatus(tankID)
+}
+
+// CloseTargets returns all enemy tanks in range (see core.CloseTargets).
+func (c *Client) CloseTargets(tankID string, filter ...string) (remote.JsonTargets, error) {
+	f := filters(filter)
+	return c.TcpClient.CloseTargets(tankID, f[0], f[1], f[2], f[3], f[4])
+}
+
+// PossibleTargets returns all enemy tanks that can be hit (see core.PossibleTargets).
+func (c *Client) PossibleTargets(tankID string, filter ...string) (remote.JsonTargets, error) {
+	f := filters(filter)
+	return c.TcpClient.PossibleTargets(tankID, f[0], f[1], f[2], f[3], f[4])
+}
+
+//---------------- HELPER --------------------------------------------------------------------------------------------//
+
+// filters returns the max. five filters of CloseTargets and PossibleTargets (unused filters are empty).
+func filters(filter []string) [5]string {
+	var f [5]string
+	copy(f[:], filter)
+	return f
+}
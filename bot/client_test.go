@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"github.com/SchnorcherSepp/TankWars/remote"
+	"testing"
+)
+
+// testClient calls the command handlers of the server directly (see remote.Execute).
+func testClient(w *core.World, owner string) *remote.TcpClient {
+	return remote.NewLocalClient(func(cmd string) string {
+		return remote.Execute(w, nil, owner, cmd)
+	})
+}
+
+func TestClient(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	w.SetCash(1000, 1000)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponRockets)
+	blue.SetPosition(core.NewPosition(400, 300), core.West)
+	w.AddTank(blue)
+
+	c := NewClient(testClient(w, core.RedTank))
+	if c.Me() != core.RedTank {
+		t.Error("wrong value", c.Me())
+	}
+
+	// getter
+	jw, err := c.World()
+	if err != nil || len(jw.Tanks) != 2 {
+		t.Error("wrong value", jw, err)
+	}
+	jt, err := c.Tank(red.ID())
+	if err != nil || jt.ID != red.ID() || jt.Pos.X != 300 {
+		t.Error("wrong value", jt, err)
+	}
+	ts, err := c.PossibleTargets(red.ID())
+	if err != nil || len(ts) != 1 || ts[0].TankID != blue.ID() {
+		t.Error("wrong value", ts, err)
+	}
+
+	// setter
+	if err := c.Stop(red.ID()); err != nil {
+		t.Error(err)
+	}
+	if err := c.SetMacro(red.ID(), core.MacroGuardMode); err != nil || red.MacroName() != core.MacroGuardMode {
+		t.Error("wrong value", err)
+	}
+
+	// batch
+	rs, err := c.Batch("Forward "+red.ID(), "Forward "+blue.ID())
+	if err != nil || len(rs) != 2 || rs[0] != "ok" || remote.ParseError(rs[1]) != remote.ErrNoAccess {
+		t.Error("wrong value", rs, err)
+	}
+	if _, err := c.Batch(); err == nil {
+		t.Error("empty batch")
+	}
+
+	// errors
+	if id, err := c.BuyTank(5, 25, core.WeaponCannon); err == nil || err.Error() != "home base not found" || id != "" {
+		t.Error("wrong value", id, err)
+	}
+	if _, err := c.Tank("id"); err != remote.ErrTankNotFound {
+		t.Error("wrong value", err)
+	}
+	if err := c.Stop(blue.ID()); err != remote.ErrNoAccess {
+		t.Error("wrong value", err)
+	}
+	if err := c.Fire(red.ID(), 90, 100); err != (remote.ErrNotReady{Status: core.StatusReloading}) {
+		t.Error("wrong value", err)
+	}
+	if err := c.SetMacro(red.ID(), "nothing"); err == nil || err.Error() != "macro not found" {
+		t.Error("wrong value", err)
+	}
+}
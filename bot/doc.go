@@ -0,0 +1,17 @@
+// Package bot is an SDK to write AIs in Go.
+// A Bot reacts to callbacks; the Runner syncs the world and detects destroyed units and the end of the game.
+//
+//	type MyBot struct{ bot.Base }
+//
+//	func (b *MyBot) OnTick(w *remote.JsonWorld) {
+//		for _, t := range w.Tanks {
+//			if t.Owner == b.Me() && !t.ActiveMacro {
+//				_ = b.SetMacro(t.ID, core.MacroAttackMove)
+//			}
+//		}
+//	}
+//
+//	func main() {
+//		log.Fatal(bot.Connect("127.0.0.1", "3333", new(MyBot)))
+//	}
+package bot
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/remote"
+	"time"
+)
+
+// PollInterval is the waiting time of Run between two world requests without a new iteration.
+var PollInterval = 10 * time.Millisecond
+
+// Connect connects to a server and runs the bot until the game or series is over (BLOCKING!).
+func Connect(host, port string, b Bot) error {
+	tc, err := remote.NewTcpClient(host, port)
+	if err != nil {
+		return err
+	}
+	defer tc.Close()
+
+	return Run(tc, b)
+}
+
+// ConnectAs connects to a server with reserved slots, joins as player with the token
+// and runs the bot until the game or series is over (BLOCKING!).
+func ConnectAs(host, port, player, token string, b Bot) error {
+	tc, err := remote.NewTcpClient(host, port)
+	if err != nil {
+		return err
+	}
+	defer tc.Close()
+
+	if err := tc.Join(player, token); err != nil {
+		return err
+	}
+	return Run(tc, b)
+}
+
+// Run runs the bot until the game or series is over or the connection fails (BLOCKING!).
+func Run(tc *remote.TcpClient, b Bot) error {
+	r := NewRunner(tc, b)
+	for {
+		ticked, err := r.Step()
+		if err != nil || r.Over() {
+			return err
+		}
+		if !ticked {
+			time.Sleep(PollInterval)
+		}
+	}
+}
+
+//---------------- RUNNER --------------------------------------------------------------------------------------------//
+
+// Runner syncs the world and calls the callbacks of the bot (see Step).
+type Runner struct {
+	bot     Bot
+	client  *Client
+	started bool
+	over    bool              // the game or series is over (see Over)
+	ended   bool              // the current game is over (see OnGameOver)
+	game    int               // current game of the series (see remote.JsonSeries)
+	synced  bool              // the first world is received
+	last    uint64            // last iteration
+	units   []remote.JsonTank // units of the last iteration
+	red     bool              // red had units
+	blue    bool              // blue had units
+}
+
+// NewRunner returns a new runner for the bot.
+func NewRunner(tc *remote.TcpClient, b Bot) *Runner {
+	return &Runner{
+		bot:    b,
+		client: NewClient(tc),
+	}
+}
+
+// Client returns the client of the bot.
+func (r *Runner) Client() *Client {
+	return r.client
+}
+
+// Over returns true if the game is over.
+// In a series (see remote.Server.SetSeries), the runner waits for the next game until the series is decided.
+func (r *Runner) Over() bool {
+	return r.over
+}
+
+// Step requests the world and calls the callbacks of the bot.
+// Returns false if there is no new iteration or the world is frozen.
+func (r *Runner) Step() (ticked bool, err error) {
+	if r.over {
+		return false, nil // EXIT
+	}
+	if !r.started {
+		r.started = true
+		if js, err := r.client.Series(); err == nil {
+			r.game = js.Game
+		}
+		r.bot.OnStart(r.client)
+	}
+	if r.ended {
+		return false, r.next() // wait for the next game of the series
+	}
+
+	// sync world
+	w, err := r.client.World()
+	if err != nil {
+		return false, err
+	}
+	finished := w.State == core.StateFinished
+	if !finished && (w.Freeze || (r.synced && w.Iteration == r.last)) {
+		return false, nil // nothing new
+	}
+	r.synced = true
+	r.last = w.Iteration
+
+	// destroyed units
+	alive := make(map[string]bool, len(w.Tanks))
+	for _, t := range w.Tanks {
+		alive[t.ID] = true
+	}
+	for _, t := range r.units {
+		if !alive[t.ID] {
+			r.bot.OnUnitDestroyed(t)
+		}
+	}
+	r.units = w.Tanks
+
+	// game over
+	r.red = r.red || w.UnitCountRed > 0
+	r.blue = r.blue || w.UnitCountBlue > 0
+	if finished || (r.red && r.blue && (w.UnitCountRed == 0 || w.UnitCountBlue == 0)) {
+		r.ended = true
+		winner := w.Winner // the result of the server (e.g. forfeit)
+		if !finished && w.UnitCountRed > 0 {
+			winner = core.RedTank
+		} else if !finished && w.UnitCountBlue > 0 {
+			winner = core.BlueTank
+		}
+		r.bot.OnGameOver(winner)
+		return true, r.next()
+	}
+
+	// tick
+	r.bot.OnTick(w)
+	return true, nil
+}
+
+// next checks the series after a game is over (see remote.JsonSeries).
+// The runner is over if the series is decided or the server has no series.
+// If the next game has started, the state of the last game is reset and the player is updated, because
+// the sides can be swapped.
+func (r *Runner) next() error {
+	js, err := r.client.Series()
+	if err != nil || js.Over {
+		r.over = true
+		return nil // EXIT: no series or decided
+	}
+	if js.Game == r.game {
+		return nil // wait
+	}
+
+	// next game
+	me, err := r.client.MyName()
+	if err != nil {
+		return err
+	}
+	r.client.me = me
+	r.game = js.Game
+	r.ended, r.synced, r.last, r.units, r.red, r.blue = false, false, 0, nil, false, false
+	return nil
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"github.com/SchnorcherSepp/TankWars/maps"
+	"github.com/SchnorcherSepp/TankWars/remote"
+	"testing"
+)
+
+// testBot records all callbacks.
+type testBot struct {
+	Base
+	ticks     []uint64
+	destroyed []string
+	winner    string
+	over      int
+}
+
+func (b *testBot) OnTick(w *remote.JsonWorld) {
+	b.ticks = append(b.ticks, w.Iteration)
+}
+
+func (b *testBot) OnUnitDestroyed(t remote.JsonTank) {
+	b.destroyed = append(b.destroyed, t.ID)
+}
+
+func (b *testBot) OnGameOver(winner string) {
+	b.winner = winner
+	b.over++
+}
+
+func TestRunner(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	_, _, t2, a2 := maps.InitOpenField(w)
+
+	b := new(testBot)
+	r := NewRunner(testClient(w, core.BlueTank), b)
+
+	// start
+	if ticked, err := r.Step(); !ticked || err != nil || b.Client == nil || b.Me() != core.BlueTank {
+		t.Fatal("wrong value", ticked, err)
+	}
+
+	// no new iteration
+	if ticked, err := r.Step(); ticked || err != nil || len(b.ticks) != 1 {
+		t.Error("wrong value", ticked, err, b.ticks)
+	}
+
+	// frozen
+	w.Freeze(true)
+	w.Update()
+	if ticked, _ := r.Step(); ticked {
+		t.Error("frozen world")
+	}
+	w.Freeze(false)
+
+	// destroyed units
+	w.Update()
+	t2.Remove()
+	a2.Remove()
+	if ticked, _ := r.Step(); !ticked || len(b.destroyed) != 2 || b.destroyed[0] != t2.ID() || b.destroyed[1] != a2.ID() {
+		t.Error("wrong value", b.destroyed)
+	}
+
+	// game over
+	w.Update()
+	w.Clear(core.BlueBase)
+	if ticked, _ := r.Step(); !ticked || !r.Over() || b.over != 1 || b.winner != core.RedTank {
+		t.Error("wrong value", b.over, b.winner)
+	}
+	if ticked, _ := r.Step(); ticked || b.over != 1 || len(b.ticks) != 2 {
+		t.Error("wrong value", b.over, b.ticks)
+	}
+}
+
+func TestRun(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	w.Clear("")
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	w.AddTank(red)
+
+	// blue has never had units: the game is not over
+	b := new(testBot)
+	r := NewRunner(testClient(w, core.RedTank), b)
+	if _, err := r.Step(); err != nil || r.Over() || len(b.ticks) != 1 {
+		t.Error("wrong value", err, b.ticks)
+	}
+
+	// connection error
+	if err := Run(testClient(nil, core.RedTank), b); err == nil || err.Error() != "invalid world status" {
+		t.Error("wrong value", err)
+	}
+}
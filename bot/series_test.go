@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"github.com/SchnorcherSepp/TankWars/remote"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seriesBot records the player and the winner of every game.
+type seriesBot struct {
+	Base
+	mux     sync.Mutex
+	players []string
+	winners []string
+}
+
+func (b *seriesBot) OnGameOver(winner string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.players = append(b.players, b.Me())
+	b.winners = append(b.winners, winner)
+}
+
+func TestRun_Series(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	s := remote.NewServer(w)
+	if err := s.SetSeries(3, true, 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadMap("test"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.ListenTCP("localhost", "3343")
+	}()
+	time.Sleep(400 * time.Millisecond) // wait for server
+
+	// the bot plays red in the first game
+	b := new(seriesBot)
+	done := make(chan error, 1)
+	go func() {
+		done <- Connect("localhost", "3343", b)
+	}()
+	time.Sleep(100 * time.Millisecond) // wait for red
+	blue, err := remote.NewTcpClient("localhost", "3343")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blue.Close()
+
+	// win is a helper function and destroys all units of the loser
+	win := func(loser string) {
+		w.Update()
+		w.Lock()
+		w.Clear(loser)
+		w.Unlock()
+		w.Update()
+		time.Sleep(400 * time.Millisecond) // wait for the bot and the next game
+	}
+
+	// game 1: the bot wins as red and the sides are swapped
+	win(core.BlueTank)
+	select {
+	case err := <-done:
+		t.Fatal("runner stopped after the first game", err)
+	default:
+	}
+
+	// game 2: the bot wins again as blue (2 of 3)
+	win(core.RedTank)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("wrong value", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("runner not stopped after the series")
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if len(b.players) != 2 || b.players[0] != core.RedTank || b.players[1] != core.BlueTank {
+		t.Error("wrong value", b.players)
+	}
+	if len(b.winners) != 2 || b.winners[0] != core.RedTank || b.winners[1] != core.BlueTank {
+		t.Error("wrong value", b.winners)
+	}
+}
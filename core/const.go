@@ -18,9 +18,44 @@ const (
 	MacroFireAndManeuver = "FireAndManeuver"
 	MacroFireWall        = "FireWall"
 	MacroGuardMode       = "GuardMode"
+	MacroKite            = "Kite"
+	MacroMoveTo          = "MoveTo"
+	MacroRetreat         = "Retreat"
+	MacroDodge           = "Dodge"
+	MacroBehavior        = "Behavior"
+	MacroPatrol          = "Patrol"
+	MacroFollow          = "Follow"
+	MacroEscort          = "Escort"
 	MacroReset           = "nil"
 )
 
+// macro phases (see MacroState)
+const (
+	PhaseMoving      = "moving"      // on the way to a position
+	PhaseArrived     = "arrived"     // the position is reached
+	PhaseBlocked     = "blocked"     // the path is blocked
+	PhaseGuarding    = "guarding"    // waiting for targets
+	PhaseAttacking   = "attacking"   // fighting the target
+	PhaseHunting     = "hunting"     // chasing the target
+	PhaseFollowing   = "following"   // following the leader
+	PhaseRetreating  = "retreating"  // moving away from the threat or back to the base
+	PhaseApproaching = "approaching" // moving toward the threat
+	PhaseRepairing   = "repairing"   // waiting at the base until fully repaired
+	PhaseDodging     = "dodging"     // evading a projectile
+	PhaseError       = "error"       // the macro has failed (see DefineMacro)
+)
+
+// target strategies
+const (
+	StrategyRotation       = "Rotation"       // the target with the fewest rotations (default)
+	StrategyClosest        = "Closest"        // the closest target
+	StrategyLowestHealth   = "LowestHealth"   // the target with the lowest health
+	StrategyHighestThreat  = "HighestThreat"  // the target with the highest damage per second against this tank
+	StrategyBuildingsFirst = "BuildingsFirst" // objects without weapon (bases) before tanks
+	StrategyFocusFire      = "FocusFire"      // the target already being shot by allies
+	StrategyNoOverkill     = "NoOverkill"     // skip targets that will die from projectiles already in flight
+)
+
 // weapons
 const (
 	ShowExplosionIterations = 10               // duration of the explosion animation
@@ -33,6 +68,7 @@ const (
 // tank attr
 const (
 	TankRotationDelay = 467 * GameSpeed / 1000 // rotation delay in iterations (~467 ms)
+	TankMaxHealth     = 100                    // health of a new tank
 	TankBudget        = 100                    // max. points = armor + damage + speed
 	TankMinSpeed      = 25                     // min. Speed (calc budget-armor-damage)
 	TankMinArmor      = 5                      // min armor
@@ -41,6 +77,40 @@ const (
 	TankMaxDamage     = 70                     // max damage
 )
 
+// base repair
+const (
+	BaseRepairRadius = 3 * BlockSize // units within this radius of their own base regain health
+	BaseRepairDelay  = GameSpeed     // iterations per regained health point (~1 HP per second)
+)
+
+// game events (see World.Events)
+const (
+	EventLogSize   = 1024         // number of events kept by the world
+	EventFired     = "fired"      // a tank has fired a projectile
+	EventExploded  = "exploded"   // a projectile has exploded
+	EventHit       = "hit"        // a tank was hit (see Event.Damage and Event.Attacker)
+	EventDestroyed = "destroyed"  // a tank was destroyed
+	EventSpawned   = "spawned"    // a tank was bought and placed near the home base
+	EventCashSpent = "cash_spent" // a player has spent cash (see Event.Cash)
+	EventStarted   = "started"    // a new game has started (see World.Start)
+	EventFinished  = "finished"   // the game is over (see Event.Owner: the winner)
+)
+
+// game states (see World.State)
+const (
+	StateWaiting   = "waiting"   // the world is frozen until the game starts (see World.Wait)
+	StateCountdown = "countdown" // the game starts after the countdown (see World.Start)
+	StateRunning   = "running"   // the game is running
+	StatePaused    = "paused"    // the running game is frozen (see World.Freeze)
+	StateFinished  = "finished"  // the game is over (see World.Result)
+)
+
+// reasons of the game result (see World.Result)
+const (
+	ReasonDestroyed = "destroyed" // all units of the loser were destroyed (no winner: both at the same time)
+	ReasonForfeit   = "forfeit"   // the loser has left the game
+)
+
 // tank angle (movement)
 const (
 	North     = 0
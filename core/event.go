@@ -0,0 +1,60 @@
+package core
+
+// Event is something that happened in the world (see World.Events).
+type Event struct {
+	Seq       uint64   // sequence number of the event (starts with 1)
+	Iteration uint64   // iteration of the world
+	Type      string   // see EventFired, EventHit, ...
+	TankID    string   // the concerned tank (the shooter, the hit or destroyed tank, the buying or spawned tank)
+	Owner     string   // owner of the concerned tank
+	Attacker  string   // tank id of the shooter (EventHit and EventDestroyed; may be empty)
+	Damage    int      // effective damage (EventHit)
+	Cash      int      // spent cash (EventCashSpent)
+	Pos       Position // position of the event
+}
+
+// Events returns all events with a sequence number greater than sinceSeq (oldest first).
+// The world keeps at least the last EventLogSize events.
+func (w *World) Events(sinceSeq uint64) []Event {
+	list := make([]Event, 0)
+	for _, e := range w.events {
+		if e.Seq > sinceSeq {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// EventSeq returns the sequence number of the last event.
+func (w *World) EventSeq() uint64 {
+	return w.eventSeq
+}
+
+// emit adds an event to the world (the world may be nil).
+func (w *World) emit(typ string, t *Tank, attacker *Tank, pos Position) *Event {
+	if w == nil {
+		return nil
+	}
+
+	// new event
+	w.eventSeq++
+	e := Event{
+		Seq:       w.eventSeq,
+		Iteration: w.iteration,
+		Type:      typ,
+		Pos:       pos,
+	}
+	if t != nil {
+		e.TankID, e.Owner = t.id, t.owner
+	}
+	if attacker != nil {
+		e.Attacker = attacker.id
+	}
+
+	// drop old events
+	if len(w.events) >= 2*EventLogSize {
+		w.events = append(make([]Event, 0, 2*EventLogSize), w.events[len(w.events)-EventLogSize+1:]...)
+	}
+	w.events = append(w.events, e)
+	return &w.events[len(w.events)-1]
+}
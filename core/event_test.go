@@ -0,0 +1,78 @@
+package core
+
+import (
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestWorld_Events(t *testing.T) {
+	resources.MuteSound = true
+
+	w := NewWorld(100, 100)
+	w.SetCash(TankBudget, 0)
+	base, _ := NewTank(w, RedBase, 30, 30, WeaponNone)
+	base.SetPosition(NewPosition(500, 500), North)
+	w.AddTank(base)
+
+	// buy
+	red, _ := NewTank(w, RedTank, 5, 70, WeaponCannon)
+	if err := w.BuyTank(red); err != nil {
+		t.Fatal(err)
+	}
+	red.SetPosition(NewPosition(100, 100), East)
+	blue, _ := NewTank(w, BlueTank, 5, 15, WeaponCannon)
+	blue.SetPosition(NewPosition(300, 100), East)
+	w.AddTank(blue)
+
+	// fight
+	for i := 0; i < 1000 && blue.Alive(); i++ {
+		red.Fire(East, 0)
+		w.Update()
+	}
+	if blue.Alive() {
+		t.Fatal("blue is alive")
+	}
+
+	// check
+	es := w.Events(0)
+	want := []string{EventCashSpent, EventSpawned, EventFired, EventExploded, EventHit}
+	if len(es) < len(want) {
+		t.Fatal("wrong value", es)
+	}
+	for i, typ := range want {
+		if es[i].Type != typ || es[i].Seq != uint64(i+1) {
+			t.Error("wrong value", i, es[i])
+		}
+	}
+	if es[0].Cash != TankBudget || es[1].TankID != red.ID() || es[4].TankID != blue.ID() || es[4].Attacker != red.ID() || es[4].Damage <= 0 {
+		t.Error("wrong value", es[:5])
+	}
+	if last := es[len(es)-1]; last.Type != EventDestroyed || last.TankID != blue.ID() || last.Attacker != red.ID() || last.Owner != BlueTank {
+		t.Error("wrong value", last)
+	}
+
+	// since
+	if es := w.Events(w.EventSeq() - 1); len(es) != 1 || es[0].Type != EventDestroyed {
+		t.Error("wrong value", es)
+	}
+	if es := w.Events(w.EventSeq()); len(es) != 0 {
+		t.Error("wrong value", es)
+	}
+}
+
+func TestWorld_Events_LogSize(t *testing.T) {
+	w := NewWorld(100, 100)
+	for i := 0; i < 3*EventLogSize; i++ {
+		w.emit(EventFired, nil, nil, Position{})
+	}
+	es := w.Events(0)
+	if len(es) < EventLogSize || len(es) > 2*EventLogSize || es[len(es)-1].Seq != 3*EventLogSize {
+		t.Error("wrong value", len(es))
+	}
+
+	// nil world
+	var nw *World
+	if e := nw.emit(EventFired, nil, nil, Position{}); e != nil {
+		t.Error("wrong value", e)
+	}
+}
@@ -1,8 +1,9 @@
 package core
 
 // TestInitialization allows setting non-exported variables outside the core packet.
-func (p *Projectile) TestInitialization(world *World, parent *Tank, pos, startPos, endPos Position, angle, distance, speed, damage, aoeRadius int, collision bool, exploded uint) {
+func (p *Projectile) TestInitialization(world *World, id string, parent *Tank, pos, startPos, endPos Position, angle, distance, speed, damage, aoeRadius int, collision bool, exploded uint) {
 	p.world = world
+	p.id = id
 	p.parent = parent
 	p.pos = pos
 	p.startPos = startPos
@@ -17,7 +18,7 @@ func (p *Projectile) TestInitialization(world *World, parent *Tank, pos, startPo
 }
 
 // TestInitialization allows setting non-exported variables outside the core packet.
-func (t *Tank) TestInitialization(world *World, id, owner string, weapon *Weapon, health, armor, speed int, pos Position, command, angle int, isBlocked bool, lastRotate uint64, macro func(t *Tank)) {
+func (t *Tank) TestInitialization(world *World, id, owner string, weapon *Weapon, health, armor, speed int, pos Position, command, angle int, isBlocked bool, lastRotate uint64, macro func(t *Tank), strategy string, orders []Order, macroName string, macroState MacroState) {
 	t.world = world
 	t.id = id
 	t.owner = owner
@@ -31,6 +32,10 @@ func (t *Tank) TestInitialization(world *World, id, owner string, weapon *Weapon
 	t.isBlocked = isBlocked
 	t.lastRotate = lastRotate
 	t.macro = macro
+	t.strategy = strategy
+	t.orders = orders
+	t.macroName = macroName
+	t.macroState = macroState
 }
 
 // TestInitialization allows setting non-exported variables outside the core packet.
@@ -51,7 +56,7 @@ func (w *Weapon) TestInitialization(world *World, parent *Tank, typ string, rng
 }
 
 // TestInitialization allows setting non-exported variables outside the core packet.
-func (w *World) TestInitialization(xWidth, yHeight int, iteration uint64, tanks []*Tank, projectiles []*Projectile, freeze bool, cashRed, cashBlue float64) {
+func (w *World) TestInitialization(xWidth, yHeight int, iteration uint64, tanks []*Tank, projectiles []*Projectile, freeze bool, cashRed, cashBlue float64, events []Event, eventSeq uint64, onUpdate func(w *World)) {
 	w.xWidth = xWidth
 	w.yHeight = yHeight
 	w.iteration = iteration
@@ -60,4 +65,7 @@ func (w *World) TestInitialization(xWidth, yHeight int, iteration uint64, tanks
 	w.freeze = freeze
 	w.cashRed = cashRed
 	w.cashBlue = cashBlue
+	w.events = events
+	w.eventSeq = eventSeq
+	w.onUpdate = onUpdate
 }
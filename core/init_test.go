@@ -12,6 +12,7 @@ func TestInitialization_Projectile(t *testing.T) {
 
 	o := &Projectile{
 		world:     nw,
+		id:        "7",
 		parent:    nt,
 		pos:       NewPosition(91, 92),
 		startPos:  NewPosition(93, 94),
@@ -27,7 +28,7 @@ func TestInitialization_Projectile(t *testing.T) {
 
 	// TestInitialization
 	clone := new(Projectile)
-	clone.TestInitialization(o.world, o.parent, o.pos, o.startPos, o.endPos, o.angle, o.distance, o.speed, o.damage, o.aoeRadius, o.collision, o.exploded)
+	clone.TestInitialization(o.world, o.id, o.parent, o.pos, o.startPos, o.endPos, o.angle, o.distance, o.speed, o.damage, o.aoeRadius, o.collision, o.exploded)
 
 	// compare
 	if !reflect.DeepEqual(o, clone) {
@@ -85,11 +86,15 @@ func TestInitialization_Tank(t *testing.T) {
 		isBlocked:  true,
 		lastRotate: 6,
 		macro:      nil,
+		macroName:  MacroGuardMode,
+		macroState: MacroState{Phase: PhaseAttacking, TargetID: "7", Waypoint: 8},
+		strategy:   StrategyClosest,
+		orders:     make([]Order, 0),
 	}
 
 	// TestInitialization
 	clone := new(Tank)
-	clone.TestInitialization(o.world, o.id, o.owner, o.weapon, o.health, o.armor, o.speed, o.pos, o.command, o.angle, o.isBlocked, o.lastRotate, o.macro)
+	clone.TestInitialization(o.world, o.id, o.owner, o.weapon, o.health, o.armor, o.speed, o.pos, o.command, o.angle, o.isBlocked, o.lastRotate, o.macro, o.strategy, o.orders, o.macroName, o.macroState)
 
 	// compare
 	if !reflect.DeepEqual(o, clone) {
@@ -108,11 +113,13 @@ func TestInitialization_World(t *testing.T) {
 		freeze:      true,
 		cashRed:     4,
 		cashBlue:    5,
+		events:      make([]Event, 3),
+		eventSeq:    6,
 	}
 
 	// TestInitialization
 	clone := new(World)
-	clone.TestInitialization(o.xWidth, o.yHeight, o.iteration, o.tanks, o.projectiles, o.freeze, o.cashRed, o.cashBlue)
+	clone.TestInitialization(o.xWidth, o.yHeight, o.iteration, o.tanks, o.projectiles, o.freeze, o.cashRed, o.cashBlue, o.events, o.eventSeq, o.onUpdate)
 
 	// compare
 	if !reflect.DeepEqual(o, clone) {
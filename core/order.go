@@ -0,0 +1,18 @@
+package core
+
+// OrderState is reported by an order with every update (see Order).
+type OrderState int
+
+// order states
+const (
+	OrderRunning OrderState = iota // the order is still in progress
+	OrderDone                      // the order is completed; the next order starts
+	OrderFailed                    // the order can't be completed; the remaining queue is dropped
+)
+
+// Order is a macro that reports its progress.
+// Orders are queued per tank and executed in sequence (see Tank.QueueOrder).
+type Order struct {
+	Name string                   // name and arguments (e.g. "MoveTo 300 400")
+	Run  func(t *Tank) OrderState // is called by update until the order is done or failed
+}
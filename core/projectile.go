@@ -1,14 +1,20 @@
 package core
 
 import (
+	"fmt"
 	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"sync/atomic"
 )
 
+// projectileIdPool is used to generate unique projectile IDs (see globalIdPool)
+var projectileIdPool uint64
+
 // Projectile is created by a weapon.
 // It moves in the world. It can collide with other objects and can explode.
 type Projectile struct {
 	world  *World
-	parent *Tank // don't explode on your parent tank
+	id     string // unique id (see projectileIdPool)
+	parent *Tank  // don't explode on your parent tank
 
 	pos       Position // current projectile position
 	startPos  Position // tank (start) position
@@ -32,6 +38,7 @@ type Projectile struct {
 func NewProjectile(world *World, parent *Tank, pos Position, angle, distance, speed, damage, aoeRadius int, collision bool) *Projectile {
 	return &Projectile{
 		world:     world,
+		id:        fmt.Sprintf("%d", atomic.AddUint64(&projectileIdPool, 1)),
 		parent:    parent,
 		pos:       pos,
 		startPos:  pos,
@@ -47,6 +54,11 @@ func NewProjectile(world *World, parent *Tank, pos Position, angle, distance, sp
 
 //---------------- GETTER --------------------------------------------------------------------------------------------//
 
+// ID is a unique id of this projectile.
+func (p *Projectile) ID() string {
+	return p.id
+}
+
 // Parent returns the tank from which the projectile was fired.
 func (p *Projectile) Parent() *Tank {
 	return p.parent
@@ -125,9 +137,10 @@ func (p *Projectile) Explode() {
 		}
 
 		// hit all around
+		p.world.emit(EventExploded, p.parent, nil, p.pos)
 		for _, tank := range p.world.tanks {
 			if tank != nil && IsCollided(p.pos, aoeRadius, tank.pos, BlockRadius) {
-				tank.Hit(p.damage)
+				tank.HitBy(p.damage, p.parent)
 			}
 		}
 
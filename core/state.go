@@ -0,0 +1,81 @@
+package core
+
+// State returns the game state (StateWaiting, StateCountdown, StateRunning, StatePaused or StateFinished).
+// Worlds without Wait() or Start() are always running or paused and never finished (e.g. tests and the GUI).
+func (w *World) State() string {
+	switch {
+	case w.state == StateWaiting || w.state == StateFinished:
+		return w.state
+	case w.freeze:
+		return StatePaused
+	case w.state == "":
+		return StateRunning
+	default:
+		return w.state
+	}
+}
+
+// Countdown returns the remaining iterations until the game starts (see Start).
+func (w *World) Countdown() uint64 {
+	return w.countdown
+}
+
+// Result returns the winner (RedTank, BlueTank or empty for a draw) and the reason (ReasonDestroyed or
+// ReasonForfeit) of a finished game.
+func (w *World) Result() (winner, reason string) {
+	return w.winner, w.reason
+}
+
+// Wait freezes the world until the game starts (see Start and Freeze).
+func (w *World) Wait() {
+	w.state = StateWaiting
+	w.freeze = true
+}
+
+// Start starts a new game after a countdown of n iterations (0 starts immediately).
+// The world is un-frozen, the result of the last game is removed and EventStarted is emitted.
+func (w *World) Start(countdown uint64) {
+	w.state, w.countdown = StateRunning, countdown
+	if countdown > 0 {
+		w.state = StateCountdown
+	}
+	w.winner, w.reason, w.contested = "", "", false
+	w.freeze = false
+	w.emit(EventStarted, nil, nil, Position{})
+}
+
+// Finish ends the game with the result (see Result); Update() does nothing for finished games.
+// Update() finishes the game if a player has no units left.
+func (w *World) Finish(winner, reason string) {
+	if w.state == StateFinished {
+		return // already finished
+	}
+	w.state, w.winner, w.reason = StateFinished, winner, reason
+	if e := w.emit(EventFinished, nil, nil, Position{}); e != nil {
+		e.Owner = winner
+	}
+}
+
+// checkFinished finishes the game if a player has no units left (see UnitCount).
+// The game can only end after both players had units at the same time.
+func (w *World) checkFinished() {
+	if w.state == "" {
+		return // no game (see State)
+	}
+	red, blue := w.UnitCount()
+	if red > 0 && blue > 0 {
+		w.contested = true
+		return // the battle goes on
+	}
+	if !w.contested {
+		return // not started
+	}
+
+	var winner string
+	if red > 0 {
+		winner = RedTank
+	} else if blue > 0 {
+		winner = BlueTank
+	}
+	w.Finish(winner, ReasonDestroyed)
+}
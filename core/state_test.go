@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestWorld_State(t *testing.T) {
+	w := NewWorld(1000, 1000)
+	red, _ := NewTank(w, RedTank, 5, 15, WeaponCannon)
+	red.SetPosition(NewPosition(100, 100), East)
+	blue, _ := NewTank(w, BlueTank, 5, 15, WeaponCannon)
+	blue.SetPosition(NewPosition(800, 800), West)
+	w.AddTank(red)
+	w.AddTank(blue)
+
+	// no game
+	if w.State() != StateRunning {
+		t.Error("wrong value", w.State())
+	}
+	w.Freeze(true)
+	if w.State() != StatePaused {
+		t.Error("wrong value", w.State())
+	}
+
+	// waiting
+	w.Wait()
+	w.Update()
+	if w.State() != StateWaiting || !w.IsFrozen() || w.Iteration() != 0 {
+		t.Error("wrong value", w.State(), w.IsFrozen(), w.Iteration())
+	}
+
+	// countdown
+	w.Start(3)
+	w.UpdateN(2)
+	if w.State() != StateCountdown || w.Countdown() != 1 || w.Iteration() != 0 {
+		t.Error("wrong value", w.State(), w.Countdown(), w.Iteration())
+	}
+	w.Update()
+	w.Update()
+	if w.State() != StateRunning || w.Iteration() != 1 {
+		t.Error("wrong value", w.State(), w.Iteration())
+	}
+
+	// finished
+	w.Clear(BlueTank)
+	w.Update()
+	if winner, reason := w.Result(); w.State() != StateFinished || winner != RedTank || reason != ReasonDestroyed {
+		t.Error("wrong value", w.State(), winner, reason)
+	}
+	if es := w.Events(0); len(es) == 0 || es[len(es)-1].Type != EventFinished || es[len(es)-1].Owner != RedTank {
+		t.Error("wrong value", es)
+	}
+	w.Update()
+	if w.Iteration() != 2 {
+		t.Error("wrong value", w.Iteration())
+	}
+
+	// new game
+	w.Reset()
+	if winner, reason := w.Result(); w.State() != StateRunning || winner != "" || reason != "" {
+		t.Error("wrong value", w.State(), winner, reason)
+	}
+}
+
+func TestWorld_Finish(t *testing.T) {
+	w := NewWorld(1000, 1000)
+	w.Start(0)
+
+	// a game without units of both players never ends
+	w.UpdateN(5)
+	if w.State() != StateRunning {
+		t.Error("wrong value", w.State())
+	}
+
+	w.Finish(BlueTank, ReasonForfeit)
+	w.Finish(RedTank, ReasonDestroyed) // already finished
+	if winner, reason := w.Result(); w.State() != StateFinished || winner != BlueTank || reason != ReasonForfeit {
+		t.Error("wrong value", w.State(), winner, reason)
+	}
+	w.Freeze(true)
+	if w.State() != StateFinished {
+		t.Error("wrong value", w.State())
+	}
+}
@@ -30,7 +30,19 @@ type Tank struct {
 	lastRotate uint64   // iteration of the last rotate command
 
 	// macro function
-	macro func(t *Tank) // is called by update
+	macro      func(t *Tank) // is called by update
+	macroName  string        // name of the macro (see MacroAttackMove, MacroGuardMode, ...)
+	macroState MacroState    // reported by the macro (see SetMacroState)
+	strategy   string        // target selection of the macros (see StrategyRotation, StrategyClosest, ...)
+	orders     []Order       // order queue; the first order is called by update if there is no macro
+}
+
+// MacroState describes what a macro is currently doing.
+// It is reported by the macro itself (see Tank.SetMacroState).
+type MacroState struct {
+	Phase    string // e.g. PhaseMoving, PhaseAttacking, PhaseRetreating, ...
+	TargetID string // id of the current target, threat or leader
+	Waypoint int    // index of the current waypoint (see MacroPatrol)
 }
 
 // NewTank return a new tank.
@@ -73,7 +85,7 @@ func NewTank(world *World, owner string, armor, damage int, weapon string) (*Tan
 		weapon: nil, // is set below
 
 		// base attribute
-		health: 100,
+		health: TankMaxHealth,
 		armor:  armor,
 		speed:  speed,
 
@@ -107,6 +119,11 @@ func (t *Tank) ID() string {
 	return t.id
 }
 
+// World returns the world in which the tank is located.
+func (t *Tank) World() *World {
+	return t.world
+}
+
 // Owner returns who control this object.
 func (t *Tank) Owner() string {
 	return t.owner
@@ -177,6 +194,54 @@ func (t *Tank) ActiveMacro() bool {
 	return t.macro != nil
 }
 
+// Macro returns the active macro function or nil.
+// see SetMacro().
+func (t *Tank) Macro() func(t *Tank) {
+	return t.macro
+}
+
+// MacroName returns the name of the active macro.
+// It is empty if there is no macro or the macro has no name.
+// see SetNamedMacro().
+func (t *Tank) MacroName() string {
+	return t.macroName
+}
+
+// MacroState returns what the active macro or order is currently doing.
+// see SetMacroState().
+func (t *Tank) MacroState() MacroState {
+	return t.macroState
+}
+
+// Orders returns the names of all queued orders.
+// The first order is the active one.
+// see QueueOrder().
+func (t *Tank) Orders() []string {
+	list := make([]string, 0, len(t.orders))
+	for _, o := range t.orders {
+		list = append(list, o.Name)
+	}
+	return list
+}
+
+// Strategy returns the target selection strategy used by macros.
+// An empty string is the default (see StrategyRotation).
+// see SetStrategy().
+func (t *Tank) Strategy() string {
+	return t.strategy
+}
+
+// EffectiveDamage returns the damage this tank would take from a hit with the given damage.
+// The damage is reduced by Armor(), but at least 1.
+// see Hit()
+func (t *Tank) EffectiveDamage(damage int) int {
+	damage -= t.armor
+	if damage < 1 {
+		damage = 1 // minimum damage
+	}
+	return damage
+}
+
 // Status returns the weapon status: (StatusMoving, StatusPreparing, StatusReloading, StatusReady or StatusNoWeapon).
 // see Weapon.Status
 func (t *Tank) Status() (rdy bool, status string) {
@@ -209,18 +274,23 @@ func (t *Tank) FireAt(pos Position) (success bool, txt string) {
 // Reduce the damage by Armor().
 // Call Remove() for death tanks.
 func (t *Tank) Hit(damage int) {
+	t.HitBy(damage, nil)
+}
 
-	// calc damage with armor
-	damage -= t.armor
-	if damage < 1 {
-		damage = 1 // minimum damage
-	}
+// HitBy is Hit() with the shooter of the projectile (may be nil).
+// The hit and the death are recorded as events (see World.Events).
+func (t *Tank) HitBy(damage int, attacker *Tank) {
 
-	// remove HP
-	t.health -= damage
+	// remove HP (damage reduced by armor)
+	effective := t.EffectiveDamage(damage)
+	t.health -= effective
+	if e := t.world.emit(EventHit, t, attacker, t.pos); e != nil {
+		e.Damage = effective
+	}
 
 	// check death
 	if !t.Alive() {
+		t.world.emit(EventDestroyed, t, attacker, t.pos)
 		t.Remove()
 	}
 }
@@ -250,7 +320,47 @@ func (t *Tank) SetPosition(pos Position, angle int) {
 // SetMacro sets a macro that is called with every update.
 // Remove it with 'nil'.
 func (t *Tank) SetMacro(macro func(t *Tank)) {
+	t.SetNamedMacro("", macro)
+}
+
+// SetNamedMacro sets a macro with a name that is called with every update.
+// The macro state is reset.
+// Remove it with 'nil'.
+func (t *Tank) SetNamedMacro(name string, macro func(t *Tank)) {
+	if macro == nil {
+		name = ""
+	}
 	t.macro = macro
+	t.macroName = name
+	t.macroState = MacroState{}
+}
+
+// SetMacroState is called by macros and orders to report what they are currently doing.
+func (t *Tank) SetMacroState(state MacroState) {
+	t.macroState = state
+}
+
+// QueueOrder appends an order to the order queue.
+// The orders are executed in sequence as long as no macro is set (see SetMacro()).
+// If an order fails, the remaining queue is dropped.
+func (t *Tank) QueueOrder(o Order) {
+	if o.Run != nil {
+		t.orders = append(t.orders, o)
+	}
+}
+
+// ClearOrders removes all queued orders.
+func (t *Tank) ClearOrders() {
+	t.orders = nil
+	if t.macro == nil {
+		t.macroState = MacroState{}
+	}
+}
+
+// SetStrategy sets the target selection strategy used by macros.
+// Reset it with "" (see StrategyRotation, StrategyClosest, ...).
+func (t *Tank) SetStrategy(strategy string) {
+	t.strategy = strategy
 }
 
 //---------------- MOVE (Setter) -------------------------------------------------------------------------------------//
@@ -366,5 +476,24 @@ func (t *Tank) Update() {
 	// call macro function
 	if t.macro != nil {
 		t.macro(t)
+	} else {
+		t.RunOrders()
+	}
+}
+
+// RunOrders calls the first order of the order queue.
+// It is called by Update() if there is no macro; macros can call it to resume the queue.
+func (t *Tank) RunOrders() {
+	if len(t.orders) == 0 {
+		return // EXIT
+	}
+
+	switch t.orders[0].Run(t) {
+	case OrderDone:
+		t.orders = t.orders[1:] // next order
+		t.macroState = MacroState{}
+	case OrderFailed:
+		t.orders = nil // drop queue
+		t.macroState = MacroState{}
 	}
 }
@@ -502,3 +502,119 @@ func TestTank_SetMacro(t *testing.T) {
 		t.Error("wrong value")
 	}
 }
+
+func TestTank_EffectiveDamage(t *testing.T) {
+	nt, _ := NewTank(nil, "TestOwner", 11, 19, WeaponCannon)
+
+	if d := nt.EffectiveDamage(0); d != 1 { // minimum damage 1
+		t.Error("wrong value", d)
+	}
+	if d := nt.EffectiveDamage(11); d != 1 { // minimum damage 1
+		t.Error("wrong value", d)
+	}
+	if d := nt.EffectiveDamage(20); d != 20-11 {
+		t.Error("wrong value", d)
+	}
+	if nt.Health() != 100 { // no hit
+		t.Error("wrong value")
+	}
+}
+
+func TestTank_SetStrategy(t *testing.T) {
+	nt, _ := NewTank(nil, "ss", 11, 22, WeaponCannon)
+
+	if nt.Strategy() != "" {
+		t.Error("wrong value")
+	}
+	nt.SetStrategy(StrategyClosest)
+	if nt.Strategy() != StrategyClosest {
+		t.Error("wrong value")
+	}
+	nt.SetStrategy("")
+	if nt.Strategy() != "" {
+		t.Error("wrong value")
+	}
+}
+
+func TestTank_QueueOrder(t *testing.T) {
+	nt, _ := NewTank(nil, "ss", 11, 22, WeaponCannon)
+
+	// queue
+	runs := 0
+	done := Order{Name: "done", Run: func(t *Tank) OrderState { runs++; return OrderDone }}
+	fail := Order{Name: "fail", Run: func(t *Tank) OrderState { runs++; return OrderFailed }}
+	nt.QueueOrder(Order{Name: "nil"}) // ignored
+	nt.QueueOrder(done)
+	nt.QueueOrder(fail)
+	nt.QueueOrder(done)
+	if o := nt.Orders(); len(o) != 3 || o[0] != "done" || o[1] != "fail" || o[2] != "done" {
+		t.Error("wrong value", o)
+	}
+
+	// paused by macro
+	nt.SetMacro(func(t *Tank) {})
+	nt.Update()
+	if runs != 0 || len(nt.Orders()) != 3 {
+		t.Error("wrong value", runs, nt.Orders())
+	}
+	nt.SetMacro(nil)
+
+	// done -> next
+	nt.Update()
+	if runs != 1 || len(nt.Orders()) != 2 || nt.Orders()[0] != "fail" {
+		t.Error("wrong value", runs, nt.Orders())
+	}
+
+	// failed -> drop queue
+	nt.Update()
+	nt.Update()
+	if runs != 2 || len(nt.Orders()) != 0 {
+		t.Error("wrong value", runs, nt.Orders())
+	}
+
+	// run by a macro
+	nt.QueueOrder(done)
+	nt.SetMacro(func(t *Tank) { t.RunOrders() })
+	nt.Update()
+	if runs != 3 || len(nt.Orders()) != 0 {
+		t.Error("wrong value", runs, nt.Orders())
+	}
+	nt.SetMacro(nil)
+	nt.RunOrders() // empty queue
+
+	// clear
+	nt.QueueOrder(done)
+	nt.ClearOrders()
+	if len(nt.Orders()) != 0 {
+		t.Error("wrong value", nt.Orders())
+	}
+}
+
+func TestTank_SetNamedMacro(t *testing.T) {
+	nt, _ := NewTank(nil, "ss", 11, 22, WeaponCannon)
+
+	// named
+	nt.SetNamedMacro(MacroGuardMode, func(t *Tank) {
+		t.SetMacroState(MacroState{Phase: PhaseAttacking, TargetID: "7"})
+	})
+	nt.Update()
+	if !nt.ActiveMacro() || nt.MacroName() != MacroGuardMode || nt.MacroState().Phase != PhaseAttacking || nt.MacroState().TargetID != "7" {
+		t.Error("wrong value", nt.MacroName(), nt.MacroState())
+	}
+
+	if nt.Macro() == nil {
+		t.Error("wrong value")
+	}
+
+	// anonymous
+	nt.SetMacro(func(t *Tank) {})
+	if !nt.ActiveMacro() || nt.MacroName() != "" || nt.MacroState() != (MacroState{}) {
+		t.Error("wrong value", nt.MacroName(), nt.MacroState())
+	}
+
+	// remove
+	nt.SetNamedMacro(MacroGuardMode, nil)
+	if nt.ActiveMacro() || nt.MacroName() != "" {
+		t.Error("wrong value", nt.MacroName())
+	}
+}
@@ -235,6 +235,7 @@ func (w *Weapon) Fire(vehiclePos Position, vehicleAngle, fireAngle, distance int
 	if w.world != nil && w.world.projectiles != nil {
 		w.world.projectiles = append(w.world.projectiles, pj)
 	}
+	w.world.emit(EventFired, w.parent, nil, vehiclePos)
 
 	// play sound
 	resources.PlaySound(resources.Sounds.Fire)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,8 +18,29 @@ type World struct {
 	projectiles []*Projectile
 
 	freeze   bool    // disable the Update() routine if true
+	speed    int     // updates per tick of the game loop (see SetSpeed)
 	cashRed  float64 // is increased by Update() as long as a red base exists
 	cashBlue float64 // is increased by Update() as long as a blue base exists
+
+	state     string // game state (see State; empty without Wait or Start)
+	countdown uint64 // remaining iterations of the countdown (see Start)
+	winner    string // winner of the finished game (see Result)
+	reason    string // reason of the result (see Result)
+	contested bool   // both players had units at the same time (see Result)
+
+	events   []Event // the last game events (see Events)
+	eventSeq uint64  // sequence number of the last event
+
+	onUpdate func(w *World) // is called at the end of every Update() (see SetOnUpdate)
+	mux      *sync.Mutex    // blocks Update() (see Lock)
+	rnd      *worldRand     // random spawn points and macros (see SetSeed)
+}
+
+// worldRand is the random number generator of a world.
+// It has its own lock, because BuyTank can be called without the world lock.
+type worldRand struct {
+	mux sync.Mutex
+	rnd *rand.Rand
 }
 
 // NewWorld create a new world.
@@ -29,8 +51,12 @@ func NewWorld(XWidth, YHeight int) *World {
 		xWidth:  XWidth,
 		yHeight: YHeight,
 
+		speed:       1,
 		tanks:       make([]*Tank, 0),
 		projectiles: make([]*Projectile, 0),
+		events:      make([]Event, 0),
+		mux:         new(sync.Mutex),
+		rnd:         &worldRand{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))},
 	}
 }
 
@@ -42,6 +68,11 @@ func (w *World) IsFrozen() bool {
 	return w.freeze
 }
 
+// Speed returns the number of updates per tick of the game loop (see SetSpeed).
+func (w *World) Speed() int {
+	return w.speed
+}
+
 // XWidth return the block width
 func (w *World) XWidth() int {
 	return w.xWidth
@@ -98,11 +129,64 @@ func (w *World) UnitCount() (red, blue int) {
 	return
 }
 
+// Random returns a random number in [0,n) of the random number generator of the world (see SetSeed).
+// Worlds that are not created by NewWorld use the global generator.
+func (w *World) Random(n int) int {
+	if w == nil || w.rnd == nil {
+		return rand.Intn(n)
+	}
+	w.rnd.mux.Lock()
+	defer w.rnd.mux.Unlock()
+
+	return w.rnd.rnd.Intn(n)
+}
+
+// HomeBase returns the base of the owner (RedTank or BlueTank) or nil if there is no base.
+func (w *World) HomeBase(owner string) *Tank {
+	var base string
+	switch owner {
+	case RedTank:
+		base = RedBase
+	case BlueTank:
+		base = BlueBase
+	default:
+		return nil // unknown owner
+	}
+
+	for _, t := range w.tanks {
+		if t != nil && t.owner == base {
+			return t
+		}
+	}
+	return nil
+}
+
 //---------------- SETTER --------------------------------------------------------------------------------------------//
 
 // Freeze disable the Update() routine if true.
+// A waiting game starts without countdown (see Wait).
 func (w *World) Freeze(status bool) {
 	w.freeze = status
+	if !status && w.state == StateWaiting {
+		w.state = StateRunning
+	}
+}
+
+// SetSeed sets the seed of the random number generator of the world (see Random), so that matches can be repeated
+// (e.g. arena.Run). Every world has its own generator.
+func (w *World) SetSeed(seed int64) {
+	if w.rnd == nil {
+		return // see NewWorld
+	}
+	w.rnd.mux.Lock()
+	defer w.rnd.mux.Unlock()
+
+	w.rnd.rnd = rand.New(rand.NewSource(seed))
+}
+
+// SetSpeed sets the number of updates per tick of the game loop (speed multiplier, see GameSpeed).
+func (w *World) SetSpeed(speed int) {
+	w.speed = speed
 }
 
 // AddTank adds a new tank to the world.
@@ -116,48 +200,67 @@ func (w *World) SetCash(cashRed, cashBlue int) {
 	w.cashRed, w.cashBlue = float64(cashRed), float64(cashBlue)
 }
 
+// SetOnUpdate sets a function that is called at the end of every Update() (e.g. to push the world to the clients).
+// It is called in the goroutine of Update() and should return quickly. Use nil to remove the function.
+func (w *World) SetOnUpdate(f func(w *World)) {
+	w.onUpdate = f
+}
+
+// Lock blocks Update() until Unlock() is called (e.g. to run several commands in the same iteration).
+// Worlds that are not created by NewWorld can't be locked.
+func (w *World) Lock() {
+	if w.mux != nil {
+		w.mux.Lock()
+	}
+}
+
+// Unlock releases the lock (see Lock).
+func (w *World) Unlock() {
+	if w.mux != nil {
+		w.mux.Unlock()
+	}
+}
+
+// errors of BuyTank
+var (
+	ErrNoBudget   = errors.New("not enough tank budget or unknown owner")
+	ErrNoHomeBase = errors.New("home base not found")
+	ErrNoSpace    = errors.New("not enough space to spawn")
+)
+
 // BuyTank buy a tank and place it near the home base.
 func (w *World) BuyTank(tank *Tank) error {
 
 	// pay credits
-	// (and get base string)
 	//-----------------------
-	var base string
 	if tank != nil && tank.owner == RedTank && w.cashRed >= TankBudget {
 		w.cashRed -= TankBudget
-		base = RedBase
 	} else if tank != nil && tank.owner == BlueTank && w.cashBlue >= TankBudget {
 		w.cashBlue -= TankBudget
-		base = BlueBase
 	} else {
 		// ERROR EXIT
 		tank.Remove() // may be nil
-		return errors.New("not enough tank budget or unknown owner")
+		return ErrNoBudget
 	}
+	w.emit(EventCashSpent, tank, nil, tank.pos).Cash = TankBudget
 
 	// find home base pos
 	//--------------------
-	var homePos = NewPosition(-1000000, -1000000)
-	for _, o := range w.tanks {
-		if o != nil && o.owner == base {
-			homePos = o.Pos()
-			break
-		}
-	}
-	if homePos.X < -100 && homePos.Y < -100 {
+	home := w.HomeBase(tank.owner)
+	if home == nil {
 		// ERROR EXIT
 		tank.Remove()
-		return errors.New("home base not found")
+		return ErrNoHomeBase
 	}
+	homePos := home.Pos()
 
 	// random spawn new tank
 	//-----------------------
-	rand.Seed(time.Now().UnixNano())
 	for try := 1; try < 1000; try++ {
 
 		// generate random spawn point
-		rndX := homePos.X + rand.Intn(4*BlockSize) - 2*BlockSize
-		rndY := homePos.Y + rand.Intn(4*BlockSize) - 2*BlockSize
+		rndX := homePos.X + w.Random(4*BlockSize) - 2*BlockSize
+		rndY := homePos.Y + w.Random(4*BlockSize) - 2*BlockSize
 		spawnPos := NewPosition(rndX, rndY)
 
 		// check collisions
@@ -172,13 +275,14 @@ func (w *World) BuyTank(tank *Tank) error {
 			// success
 			tank.Stop()
 			w.AddTank(tank)
+			w.emit(EventSpawned, tank, nil, tank.pos)
 			return nil // success EXIT
 		}
 	}
 
 	// ERROR: can't spawn tank
 	tank.Remove()
-	return errors.New("not enough space to spawn")
+	return ErrNoSpace
 }
 
 // Clear all tanks (objects) with prefix from world.
@@ -191,12 +295,26 @@ func (w *World) Clear(prefix string) {
 			list = append(list, t)
 		} else {
 			t.health = 0 // kill object
+			w.emit(EventDestroyed, t, nil, t.pos)
 		}
 	}
 
 	w.tanks = list
 }
 
+// Reset removes all tanks, buildings and projectiles and sets the iteration and the cash to 0 (e.g. to load a new map).
+// The result of a finished game is removed (see Start). The events, the speed and the freeze state are kept.
+func (w *World) Reset() {
+	w.iteration = 0
+	w.tanks = make([]*Tank, 0)
+	w.projectiles = make([]*Projectile, 0)
+	w.cashRed, w.cashBlue = 0, 0
+	w.winner, w.reason, w.contested = "", "", false
+	if w.state == StateFinished {
+		w.state = StateRunning
+	}
+}
+
 //---------------- UPDATE --------------------------------------------------------------------------------------------//
 
 // UpdateN calls Update() n-times.
@@ -209,10 +327,21 @@ func (w *World) UpdateN(n int) {
 // Update is called 30 times (see GameSpeed) per second.
 // The method also calls Update() of all tanks and all projectiles.
 func (w *World) Update() {
-	if w.freeze {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.freeze || w.state == StateFinished {
 		return // no updates
 	}
 
+	// countdown
+	if w.state == StateCountdown {
+		if w.countdown--; w.countdown == 0 {
+			w.state = StateRunning
+		}
+		return
+	}
+
 	// update all tanks
 	for _, t := range w.tanks {
 		t.Update()
@@ -232,6 +361,29 @@ func (w *World) Update() {
 		}
 	}
 
+	// repair units near their own base
+	if w.iteration%BaseRepairDelay == 0 {
+		redBase, blueBase := w.HomeBase(RedTank), w.HomeBase(BlueTank)
+		for _, t := range w.tanks {
+			if t == nil || !t.Alive() || t.health >= TankMaxHealth {
+				continue // nothing to repair
+			}
+			if t.owner == RedTank && redBase != nil && IsCollided(t.pos, 0, redBase.pos, BaseRepairRadius) {
+				t.health++
+			} else if t.owner == BlueTank && blueBase != nil && IsCollided(t.pos, 0, blueBase.pos, BaseRepairRadius) {
+				t.health++
+			}
+		}
+	}
+
+	// game over
+	w.checkFinished()
+
 	// finish this iteration
 	w.iteration++
+
+	// notify
+	if w.onUpdate != nil {
+		w.onUpdate(w)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/SchnorcherSepp/TankWars/gui/resources"
 	"testing"
+	"time"
 )
 
 func TestNewWorld(t *testing.T) {
@@ -106,6 +107,54 @@ func TestWorld_Update(t *testing.T) {
 	}
 }
 
+func TestWorld_SetOnUpdate(t *testing.T) {
+	w := NewWorld(222, 333)
+
+	var calls []uint64
+	w.SetOnUpdate(func(w *World) {
+		calls = append(calls, w.Iteration())
+	})
+	w.UpdateN(3)
+	w.Freeze(true)
+	w.Update() // frozen
+	if len(calls) != 3 || calls[0] != 1 || calls[2] != 3 {
+		t.Error("wrong value", calls)
+	}
+
+	// remove
+	w.Freeze(false)
+	w.SetOnUpdate(nil)
+	w.Update()
+	if len(calls) != 3 {
+		t.Error("wrong value", calls)
+	}
+}
+
+func TestWorld_Lock(t *testing.T) {
+	w := NewWorld(222, 333)
+
+	w.Lock()
+	done := make(chan bool)
+	go func() {
+		w.Update()
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if w.Iteration() != 0 {
+		t.Error("update is not blocked")
+	}
+	w.Unlock()
+	<-done
+	if w.Iteration() != 1 {
+		t.Error("wrong value", w.Iteration())
+	}
+
+	// worlds without NewWorld
+	nw := new(World)
+	nw.Lock()
+	nw.Unlock()
+}
+
 func TestWorld_BuyTank(t *testing.T) {
 	// get world
 	w := NewWorld(1000, 1000)
@@ -312,3 +361,91 @@ func TestWorld_Clear(t *testing.T) {
 		t.Error("wrong value")
 	}
 }
+
+func TestWorld_Reset(t *testing.T) {
+	w := NewWorld(1000, 1000)
+	w.SetSpeed(3)
+	w.SetCash(10, 20)
+	tank, _ := NewTank(w, RedTank, 5, 15, WeaponCannon)
+	w.AddTank(tank)
+	w.UpdateN(10)
+	w.Freeze(true)
+
+	w.Reset()
+	if len(w.Tanks()) != 0 || len(w.Projectiles()) != 0 || w.Iteration() != 0 {
+		t.Error("wrong value", len(w.Tanks()), len(w.Projectiles()), w.Iteration())
+	}
+	if r, b := w.CashStat(); r != 0 || b != 0 {
+		t.Error("wrong value", r, b)
+	}
+	if w.Speed() != 3 || !w.IsFrozen() {
+		t.Error("wrong value", w.Speed(), w.IsFrozen())
+	}
+}
+
+func TestWorld_SetSeed(t *testing.T) {
+	w1, w2 := NewWorld(10, 10), NewWorld(10, 10)
+	w1.SetSeed(42)
+	w2.SetSeed(42)
+	_ = NewWorld(10, 10).Random(100) // other worlds don't change the numbers
+	for i := 0; i < 10; i++ {
+		if a, b := w1.Random(100), w2.Random(100); a != b || a < 0 || a >= 100 {
+			t.Error("wrong value", a, b)
+		}
+	}
+
+	// worlds without generator (see NewWorld)
+	var nw *World
+	if r := nw.Random(5); r < 0 || r >= 5 {
+		t.Error("wrong value", r)
+	}
+	new(World).SetSeed(1)
+}
+
+func TestWorld_HomeBase(t *testing.T) {
+	w := NewWorld(100, 100)
+	redBase, _ := NewTank(w, RedBase, 11, 22, WeaponNone)
+	w.AddTank(redBase)
+
+	if b := w.HomeBase(RedTank); b != redBase {
+		t.Error("wrong value")
+	}
+	if b := w.HomeBase(BlueTank); b != nil {
+		t.Error("wrong value")
+	}
+	if b := w.HomeBase(NeutralRock); b != nil {
+		t.Error("wrong value")
+	}
+}
+
+func TestWorld_Update_Repair(t *testing.T) {
+	w := NewWorld(100, 100)
+	redBase, _ := NewTank(w, RedBase, 11, 22, WeaponNone)
+	redBase.SetPosition(NewPosition(500, 500), North)
+	w.AddTank(redBase)
+
+	near, _ := NewTank(w, RedTank, 5, 22, WeaponCannon)
+	near.SetPosition(NewPosition(500, 500+BaseRepairRadius-1), North)
+	near.Hit(50 + near.armor)
+	w.AddTank(near)
+	far, _ := NewTank(w, RedTank, 5, 22, WeaponCannon)
+	far.SetPosition(NewPosition(500+BaseRepairRadius, 500), North)
+	far.Hit(50 + far.armor)
+	w.AddTank(far)
+	enemy, _ := NewTank(w, BlueTank, 5, 22, WeaponCannon)
+	enemy.SetPosition(NewPosition(400, 500), North)
+	enemy.Hit(50 + enemy.armor)
+	w.AddTank(enemy)
+
+	// repair
+	w.UpdateN(10 * BaseRepairDelay)
+	if near.Health() != 60 || far.Health() != 50 || enemy.Health() != 50 {
+		t.Error("wrong value", near.Health(), far.Health(), enemy.Health())
+	}
+
+	// max health
+	w.UpdateN(100 * BaseRepairDelay)
+	if near.Health() != TankMaxHealth || redBase.Health() != TankMaxHealth {
+		t.Error("wrong value", near.Health(), redBase.Health())
+	}
+}
@@ -11,19 +11,26 @@ import (
 //####################################################################################################################//
 
 // MyAI run demo ai
-func MyAI(host, port string) {
+func MyAI(host, port string) error {
 	// connect to server
-	client := remote.NewTcpClient(host, port)
+	client, err := remote.NewTcpClient(host, port)
+	if err != nil {
+		return err
+	}
 
 	// get my player
-	me := client.MyName()
+	me, err := client.MyName()
+	if err != nil {
+		return err
+	}
 
 	// auto request world status
 	var world remote.JsonWorld
 	go func() {
 		for {
-			resp := client.GameStatus() // request game status
-			world.Set(resp)             // update world
+			if jw, err := client.GameStatus(); err == nil { // request game status
+				world = *jw // update world
+			}
 			time.Sleep(100 * time.Millisecond)
 		}
 	}()
@@ -68,11 +75,11 @@ func MyAI(host, port string) {
 
 	// use MoveTo macro to move tank to the center
 	//---------------------------------------------
-	resp := client.SetMacroMoveTo(tankID, centerX, centerY)
-	println("move tank", resp)
+	err = client.SetMacroMoveTo(tankID, centerX, centerY)
+	println("move tank", err)
 
-	resp = client.SetMacroMoveTo(rocketID, centerX, centerY)
-	println("move rocket", resp)
+	err = client.SetMacroMoveTo(rocketID, centerX, centerY)
+	println("move rocket", err)
 
 	// wait for tanks
 	//----------------
@@ -82,11 +89,11 @@ func MyAI(host, port string) {
 
 	// use GuardMode to defend the center
 	//------------------------------------
-	resp = client.SetMacro(tankID, core.MacroGuardMode)
-	println("macro tank", resp)
+	err = client.SetMacro(tankID, core.MacroGuardMode)
+	println("macro tank", err)
 
-	resp = client.SetMacro(rocketID, core.MacroGuardMode)
-	println("macro rocket", resp)
+	err = client.SetMacro(rocketID, core.MacroGuardMode)
+	println("macro rocket", err)
 
 	// wait for more money
 	//------------------------
@@ -96,8 +103,8 @@ func MyAI(host, port string) {
 
 	// buy Artillery
 	//---------------
-	resp = client.BuyTank(5, 70, core.WeaponArtillery)
-	println("buy", resp)
+	_, err = client.BuyTank(5, 70, core.WeaponArtillery)
+	println("buy", err)
 	time.Sleep(250 * time.Millisecond)
 
 	var artilleryID string
@@ -114,24 +121,24 @@ func MyAI(host, port string) {
 
 	// use MacroAttackMove on Artillery to find a way to the enemy
 	//-------------------------------------------------------------
-	resp = client.SetMacro(artilleryID, core.MacroAttackMove)
-	println("macro artillery", resp)
+	err = client.SetMacro(artilleryID, core.MacroAttackMove)
+	println("macro artillery", err)
 
-	resp = client.SetMacro(tankID, core.MacroAttackMove)
-	println("macro tank", resp)
+	err = client.SetMacro(tankID, core.MacroAttackMove)
+	println("macro tank", err)
 
-	resp = client.SetMacro(rocketID, core.MacroAttackMove)
-	println("macro rocket", resp)
+	err = client.SetMacro(rocketID, core.MacroAttackMove)
+	println("macro rocket", err)
 
 	// buy more tanks (loops)
 	//------------------------
 	for {
-		client.BuyTank(5, 70, core.WeaponArtillery)
+		_, _ = client.BuyTank(5, 70, core.WeaponArtillery)
 		time.Sleep(250 * time.Millisecond)
 
 		for _, tank := range world.Tanks {
 			if tank.Owner == me && !tank.ActiveMacro {
-				client.SetMacro(tank.ID, core.MacroAttackMove)
+				_ = client.SetMacro(tank.ID, core.MacroAttackMove)
 			}
 		}
 	}
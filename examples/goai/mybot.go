@@ -0,0 +1,56 @@
+package goai
+
+import (
+	"github.com/SchnorcherSepp/TankWars/bot"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/remote"
+)
+
+//####################################################################################################################//
+//###############  MY BOT  ###########################################################################################//
+//####################################################################################################################//
+
+// MyBot is the demo ai written with the bot package.
+// It defends the center with the start tanks and attacks with artillery.
+type MyBot struct {
+	bot.Base
+}
+
+// RunMyBot connects to the server and runs MyBot (BLOCKING!).
+func RunMyBot(host, port string) error {
+	return bot.Connect(host, port, new(MyBot))
+}
+
+// OnTick is called with every new world iteration.
+func (b *MyBot) OnTick(w *remote.JsonWorld) {
+	// defend the center
+	if w.Iteration < 800 {
+		x, y := w.ScreenWidth/2-150, w.ScreenHeight/2
+		if b.Me() == core.BlueTank {
+			x += 300
+		}
+		for _, t := range w.Tanks {
+			if t.Owner == b.Me() && !t.ActiveMacro {
+				_ = b.SetMacroMoveTo(t.ID, x, y)
+			}
+		}
+		return
+	}
+
+	// buy artillery
+	if (b.Me() == core.RedTank && w.CashRed > 100) || (b.Me() == core.BlueTank && w.CashBlue > 100) {
+		_, _ = b.BuyTank(5, 70, core.WeaponArtillery)
+	}
+
+	// attack
+	for _, t := range w.Tanks {
+		if t.Owner == b.Me() && t.Macro != core.MacroAttackMove {
+			_ = b.SetMacro(t.ID, core.MacroAttackMove)
+		}
+	}
+}
+
+// OnGameOver prints the winner.
+func (b *MyBot) OnGameOver(winner string) {
+	println("===== GAME OVER =====", winner)
+}
@@ -15,7 +15,6 @@ var _ ebiten.Game = (*Game)(nil)
 // Game is the GUI
 type Game struct {
 	world        *core.World
-	speed        int
 	xWidth       int
 	yHeight      int
 	screenWidth  int
@@ -37,11 +36,11 @@ type Game struct {
 // This call is blocking.
 func RunGame(title string, world *core.World, speed int, mute bool) error {
 	resources.MuteSound = mute
+	world.SetSpeed(speed)
 
 	// config img
 	game := &Game{
 		world:        world,
-		speed:        speed,
 		xWidth:       world.XWidth(),       // world dimension X
 		yHeight:      world.YHeight(),      // world dimension Y
 		screenWidth:  world.ScreenWidth(),  // basic image 64x64
@@ -174,6 +173,8 @@ func writeGlobalText(screen *ebiten.Image, g *Game) {
 		s += "  - '3' set AttackMove macro\n"
 		s += "  - '4' set FireWall macro\n"
 		s += "  - '5' set MoveTo(cursor) macro\n"
+		s += "  - '6' set Kite macro\n"
+		s += "  - '7' add Dodge to the macro\n"
 		s += "\n"
 	} else {
 		s += "   Press 'H' for help\n"
@@ -95,6 +95,7 @@ func drawTank(screen *ebiten.Image, t *core.Tank, owner string, active, rangeCir
 		writeTankLine2(screen, xf, yf, t) // write LINE 2
 		if debug {
 			writeTankLine3(screen, xf, yf, t) // write LINE 3
+			writeTankLine4(screen, xf, yf, t) // write LINE 4
 		}
 	}
 }
@@ -185,7 +186,9 @@ func writeTankLine2(screen *ebiten.Image, xf, yf float64, t *core.Tank) {
 	}
 
 	// add macro
-	if t.ActiveMacro() {
+	if t.ActiveMacro() && t.MacroName() != "" {
+		txt += " (" + t.MacroName() + ")"
+	} else if t.ActiveMacro() {
 		txt += " (M)"
 	}
 
@@ -205,3 +208,24 @@ func writeTankLine3(screen *ebiten.Image, xf, yf float64, t *core.Tank) {
 	yf = yf + 54
 	ebitenutil.DebugPrintAt(screen, txt, int(xf), int(yf))
 }
+
+// writeTankLine4 write the fourth line of tanks (debug: macro state)
+func writeTankLine4(screen *ebiten.Image, xf, yf float64, t *core.Tank) {
+	// generate text
+	s := t.MacroState()
+	if s.Phase == "" {
+		return // EXIT
+	}
+	txt := s.Phase
+	if s.TargetID != "" {
+		txt += " " + s.TargetID
+	}
+	if t.MacroName() == core.MacroPatrol {
+		txt += fmt.Sprintf(" #%d", s.Waypoint)
+	}
+
+	// write text
+	xf = xf - (6 / 2 * float64(len(txt)))
+	yf = yf + 66
+	ebitenutil.DebugPrintAt(screen, txt, int(xf), int(yf))
+}
@@ -28,7 +28,10 @@ func (g *Game) Update() error {
 	}
 
 	// UPDATE and RETURN
-	g.world.UpdateN(g.speed)
+	g.world.Lock()
+	speed := g.world.Speed() // see remote.Server.SetSpeed
+	g.world.Unlock()
+	g.world.UpdateN(speed)
 	return nil
 }
 
@@ -173,25 +176,31 @@ func controlsGame(g *Game) {
 
 		// macros
 		if ebiten.IsKeyPressed(ebiten.KeyQ) { // remove
-			remote.SetMacro(g.world, "", g.activeTank.ID(), core.MacroReset)
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroReset)
 		}
 		if ebiten.IsKeyPressed(ebiten.Key1) { // GuardMode
-			remote.SetMacro(g.world, "", g.activeTank.ID(), core.MacroGuardMode)
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroGuardMode)
 		}
 		if ebiten.IsKeyPressed(ebiten.Key2) { // FireAndManeuver
-			remote.SetMacro(g.world, "", g.activeTank.ID(), core.MacroFireAndManeuver)
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroFireAndManeuver)
 		}
 		if ebiten.IsKeyPressed(ebiten.Key3) { // AttackMove
-			remote.SetMacro(g.world, "", g.activeTank.ID(), core.MacroAttackMove)
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroAttackMove)
 		}
 		if ebiten.IsKeyPressed(ebiten.Key4) { // FireWall
-			remote.SetMacro(g.world, "", g.activeTank.ID(), core.MacroFireWall)
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroFireWall)
 		}
-		if ebiten.IsKeyPressed(ebiten.Key5) { // FireWall
+		if ebiten.IsKeyPressed(ebiten.Key5) { // MoveTo
 			cx, cy := ebiten.CursorPosition()
 			g.activeTank.Forward()
 			remote.SetMacroMoveTo(g.world, "", g.activeTank.ID(), strconv.Itoa(cx), strconv.Itoa(cy))
 		}
+		if ebiten.IsKeyPressed(ebiten.Key6) { // Kite
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroKite)
+		}
+		if ebiten.IsKeyPressed(ebiten.Key7) { // Dodge
+			remote.SetMacro(g.world, nil, "", g.activeTank.ID(), core.MacroDodge)
+		}
 	}
 
 	// toggle KEY R: range circles
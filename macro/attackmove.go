@@ -2,7 +2,6 @@ package macro
 
 import (
 	"github.com/SchnorcherSepp/TankWars/core"
-	"math/rand"
 )
 
 // AttackMove moves the tank in the aligned direction.
@@ -19,14 +18,8 @@ func AttackMove(t *core.Tank, filter ...string) {
 		t.Stop()
 		GuardMode(t, filter...)
 	} else {
-		if t.Blocked() {
-			// random left/right
-			if rand.Intn(2) == 1 {
-				t.Left()
-			} else {
-				t.Right()
-			}
-		}
+		report(t, core.PhaseMoving, nil)
+		unblock(t)
 		t.Forward()
 	}
 }
@@ -0,0 +1,134 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"math"
+)
+
+// dodgeMargin is added to the blast radius, because shells can overshoot their EndPos() by one tick.
+const dodgeMargin = core.BallRadius
+
+// Dodge watches enemy projectiles heading toward the tank and moves out of the predicted blast radius before impact.
+// Artillery shells explode at their EndPos(); cannon balls fly straight ahead along their Angle() (see AimedAt).
+// Without danger, the previous macro is called or the order queue of the tank is resumed (see core.Tank.RunOrders).
+// Light, fast tanks can escape the slow artillery shells.
+type Dodge struct {
+	Previous func(t *core.Tank) // the macro that is resumed after the danger (nil resumes the order queue)
+	Dodging  bool               // true while the tank is evading a projectile
+}
+
+// NewDodge returns a new dodge macro that resumes the previous macro.
+func NewDodge(previous func(t *core.Tank)) *Dodge {
+	return &Dodge{
+		Previous: previous,
+	}
+}
+
+// Update is the macro function (see core.Tank.SetMacro).
+func (d *Dodge) Update(t *core.Tank) {
+	if t == nil || d == nil {
+		return // EXIT
+	}
+
+	// evade
+	if p := incoming(t); p != nil {
+		d.Dodging = true
+		report(t, core.PhaseDodging, p.Parent())
+		if p.Collision() {
+			// Cannon: move sideways out of the flight path
+			side := (p.Angle() + 90) % 360
+			if DistanceToLine(p.Pos(), p.EndPos(), core.CalcPosFromAngle(t.Pos(), side, core.BlockSize)) <
+				DistanceToLine(p.Pos(), p.EndPos(), t.Pos()) {
+				side = (side + 180) % 360 // the other side
+			}
+			kiteMove(t, core.CalcPosFromAngle(t.Pos(), (side+180)%360, core.BlockSize), -1)
+		} else {
+			// Artillery: leave the blast radius (forward, backward or turn away from the impact point)
+			kiteMove(t, core.CalcPosFromAngle(t.Pos(), (escapeHeading(t, p)+180)%360, core.BlockSize), -1)
+		}
+		return // EXIT
+	}
+
+	// the danger is over
+	if d.Dodging {
+		d.Dodging = false
+		t.Stop()
+	}
+
+	// resume
+	if d.Previous != nil {
+		d.Previous(t)
+	} else {
+		t.RunOrders()
+	}
+}
+
+// incoming returns the enemy projectile that will hit the tank first.
+// Returns nil if the tank is safe.
+func incoming(t *core.Tank) *core.Projectile {
+	var next *core.Projectile
+	var nextDist float64
+
+	if t.World() == nil {
+		return nil
+	}
+	for _, p := range t.World().Projectiles() {
+		if p == nil || p.Parent() == nil || p.Parent().Owner() == t.Owner() || !threatens(p, t.Pos()) {
+			continue // no danger
+		}
+		impact := p.EndPos() // Artillery
+		if p.Collision() {
+			impact = t.Pos() // Cannon
+		}
+		if d := core.Distance(p.Pos(), impact); next == nil || d < nextDist {
+			next = p
+			nextDist = d
+		}
+	}
+	return next
+}
+
+// threatens returns true if the projectile will hit an object at the given position (see AimedAt).
+// The blast radius of artillery shells is extended by dodgeMargin.
+func threatens(p *core.Projectile, pos core.Position) bool {
+	if p.Collision() || p.Exploded() {
+		return AimedAt(p, pos)
+	}
+	return core.IsCollided(p.EndPos(), blastRadius(p.AoERadius())+dodgeMargin, pos, core.BlockRadius)
+}
+
+// escapeHeading returns the fastest way out of the blast radius of an artillery shell.
+// Driving forward or backward is preferred, because every rotation costs core.TankRotationDelay.
+func escapeHeading(t *core.Tank, p *core.Projectile) int {
+	radius := float64(blastRadius(p.AoERadius()) + dodgeMargin + core.BlockRadius)
+
+	// away from the impact point (incl. the rotations)
+	away := (core.RelativeAngle(t.Pos(), p.EndPos()) + 180) % 360
+	rotations := math.Abs(float64(RotationsToTarget(t, away)))
+	if rotations > 2 {
+		rotations = 4 - rotations // drive backward
+	}
+	best := away
+	bestLen := radius - core.Distance(t.Pos(), p.EndPos()) + rotations*core.TankRotationDelay*core.MovePerTick*float64(t.Speed())
+
+	// forward and backward
+	for _, heading := range []int{t.Angle(), (t.Angle() + 180) % 360} {
+		if l := escapeLength(t.Pos(), heading, p.EndPos(), radius); l < bestLen {
+			best = heading
+			bestLen = l
+		}
+	}
+	return best
+}
+
+// escapeLength returns the distance to drive from pos in the given direction to leave the circle around center.
+func escapeLength(pos core.Position, heading int, center core.Position, radius float64) float64 {
+	r := float64(heading-90) * math.Pi / 180 // see core.CalcPosFromAngle
+	dx, dy := pos.Xf-center.Xf, pos.Yf-center.Yf
+	dot := dx*math.Cos(r) + dy*math.Sin(r)
+	disc := dot*dot - (dx*dx + dy*dy) + radius*radius
+	if disc < 0 {
+		return 0 // outside the circle
+	}
+	return math.Max(0, -dot+math.Sqrt(disc))
+}
@@ -0,0 +1,130 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestDodge(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon) // light and fast
+	red.SetPosition(core.NewPosition(900, 500), core.North)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponArtillery)
+	blue.SetPosition(core.NewPosition(300, 500), core.East)
+	w.AddTank(blue)
+	w.UpdateN(int(blue.Weapon().PreparationTime()))
+
+	// test nil
+	var nilDodge *Dodge
+	nilDodge.Update(red)
+	NewDodge(nil).Update(nil)
+
+	// dodge
+	resumed := 0
+	d := NewDodge(func(t *core.Tank) { resumed++ })
+	if ok, txt := blue.FireAt(red.Pos()); !ok {
+		t.Fatal(txt)
+	}
+	dodging := false
+	for i := 0; i < 200; i++ {
+		d.Update(red)
+		w.Update()
+		if d.Dodging && red.MacroState().Phase == core.PhaseDodging && red.MacroState().TargetID == blue.ID() {
+			dodging = true
+		}
+	}
+
+	// check
+	if red.Health() != 100 || !dodging || d.Dodging || red.Moving() || resumed == 0 {
+		t.Error("wrong value", red.Health(), dodging, d.Dodging, red.Moving(), resumed)
+	}
+
+	// without dodge
+	w.UpdateN(int(blue.Weapon().ReloadTime()))
+	if ok, txt := blue.FireAt(red.Pos()); !ok {
+		t.Fatal(txt)
+	}
+	w.UpdateN(200)
+	if red.Health() == 100 {
+		t.Error("wrong value", red.Health())
+	}
+}
+
+func TestDodge_Orders(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(900, 500), core.North)
+	w.AddTank(red)
+
+	// resume the order queue
+	runs := 0
+	red.QueueOrder(core.Order{Name: "test", Run: func(t *core.Tank) core.OrderState { runs++; return core.OrderRunning }})
+	d := NewDodge(nil)
+	red.SetMacro(d.Update)
+	w.UpdateN(10)
+	if runs != 10 {
+		t.Error("wrong value", runs)
+	}
+}
+
+func TestDodge_Offset(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	for _, offset := range [][2]int{{40, 0}, {-40, 0}, {0, 40}, {30, -30}} {
+		// prepare world
+		w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+		red, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon)
+		red.SetPosition(core.NewPosition(900, 500), core.North)
+		w.AddTank(red)
+		blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponArtillery)
+		blue.SetPosition(core.NewPosition(300, 500), core.East)
+		w.AddTank(blue)
+		w.UpdateN(int(blue.Weapon().PreparationTime()))
+
+		// dodge
+		d := NewDodge(nil)
+		blue.FireAt(core.NewPosition(red.Pos().X+offset[0], red.Pos().Y+offset[1]))
+		for i := 0; i < 200; i++ {
+			d.Update(red)
+			w.Update()
+		}
+		if red.Health() != 100 {
+			t.Error("wrong value", offset, red.Health())
+		}
+	}
+}
+
+func TestDodge_Cannon(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(600, 510), core.South)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponCannon)
+	blue.SetPosition(core.NewPosition(300, 500), core.East)
+	w.AddTank(blue)
+	w.UpdateN(int(blue.Weapon().ReloadTime()))
+
+	// dodge sideways (south)
+	d := NewDodge(nil)
+	if ok, txt := blue.Fire(core.East, 9999); !ok {
+		t.Fatal(txt)
+	}
+	for i := 0; i < 60; i++ {
+		d.Update(red)
+		w.Update()
+	}
+	if red.Health() != 100 || red.Pos().Y <= 510 {
+		t.Error("wrong value", red.Health(), red.Pos().Y)
+	}
+}
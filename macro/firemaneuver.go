@@ -15,8 +15,10 @@ func FireAndManeuver(t *core.Tank) {
 	// macro
 	rdy, txt := t.Status()
 	if rdy { // ready -> fire
+		report(t, core.PhaseAttacking, nil)
 		t.Fire(t.Angle(), 99999)
 	} else if txt == core.StatusReloading { // move while reloading
+		report(t, core.PhaseMoving, nil)
 		t.Forward()
 	} else if txt == core.StatusMoving { // prepare for fire
 		t.Stop()
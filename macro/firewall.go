@@ -2,7 +2,6 @@ package macro
 
 import (
 	"github.com/SchnorcherSepp/TankWars/core"
-	"math/rand"
 )
 
 // FireWall fires at random positions in front of the tank.
@@ -13,8 +12,9 @@ func FireWall(t *core.Tank) {
 	}
 
 	// get random angle: -35° to +35°
-	angle := rand.Intn(70) - 35 + t.Angle()
+	angle := t.World().Random(70) - 35 + t.Angle()
 
 	// fire
+	report(t, core.PhaseAttacking, nil)
 	t.Fire(angle, 9999)
 }
@@ -0,0 +1,94 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// Follow keeps the tank within the given distance of the leader.
+// The tank stops when the leader is destroyed.
+// Returns core.OrderDone if the tank is close enough and core.OrderFailed if the leader is destroyed.
+func Follow(t, leader *core.Tank, distance int) core.OrderState {
+	if t == nil {
+		return core.OrderFailed // EXIT
+	}
+
+	// leader destroyed
+	if leader == nil || !leader.Alive() {
+		if t.Moving() {
+			t.Stop()
+		}
+		return core.OrderFailed // EXIT
+	}
+
+	// close enough
+	if core.Distance(t.Pos(), leader.Pos()) <= float64(distance) {
+		report(t, core.PhaseFollowing, leader)
+		if t.Moving() {
+			t.Stop()
+		}
+		return core.OrderDone // EXIT
+	}
+
+	// move
+	unblock(t)
+	MoveTo(t, leader.Pos())
+	report(t, core.PhaseFollowing, leader)
+	return core.OrderRunning
+}
+
+// Escort follows the leader (see Follow) and engages anything attacking the leader.
+// An attacker is the parent of an enemy projectile that is aimed at the leader (see AimedAt).
+type Escort struct {
+	Leader   *core.Tank // the escorted tank
+	Distance int        // see Follow
+	Attacker *core.Tank // the last known attacker
+	Filter   []string   // see core.PossibleTargets
+}
+
+// NewEscort returns a new escort for the leader.
+func NewEscort(leader *core.Tank, distance int, filter ...string) *Escort {
+	return &Escort{
+		Leader:   leader,
+		Distance: distance,
+		Filter:   filter,
+	}
+}
+
+// Update is the macro function (see core.Tank.SetMacro).
+func (e *Escort) Update(t *core.Tank) {
+	if t == nil || e == nil {
+		return // EXIT
+	}
+
+	// find new attackers
+	if e.Leader != nil && e.Leader.Alive() && t.World() != nil {
+		for _, p := range t.World().Projectiles() {
+			if p.Parent() != nil && p.Parent() != t && !isFiltered(p.Parent(), e.Filter...) && AimedAt(p, e.Leader.Pos()) {
+				e.Attacker = p.Parent()
+				break
+			}
+		}
+	}
+	if e.Attacker != nil && !e.Attacker.Alive() {
+		e.Attacker = nil // attacker destroyed
+	}
+
+	// engage the attacker
+	if e.Attacker != nil && t.Weapon() != nil && t.Weapon().Type() != core.WeaponNone {
+		for _, target := range core.PossibleTargets(t, e.Filter...) {
+			if target.Tank == e.Attacker {
+				report(t, core.PhaseAttacking, e.Attacker)
+				t.Stop()
+				Attack(t, target)
+				return // EXIT
+			}
+		}
+		// out of range
+		Follow(t, e.Attacker, t.Weapon().Range())
+		report(t, core.PhaseHunting, e.Attacker)
+		return // EXIT
+	}
+
+	// follow the leader
+	Follow(t, e.Leader, e.Distance)
+}
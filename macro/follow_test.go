@@ -0,0 +1,83 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestFollow(t *testing.T) {
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	leader, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponCannon)
+	leader.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(leader)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(300, 600), core.North)
+	w.AddTank(red)
+
+	// test nil
+	Follow(nil, leader, 100)
+	Follow(red, nil, 100)
+
+	// follow
+	leader.Forward()
+	for i := 0; i < 600; i++ {
+		Follow(red, leader, 150)
+		w.Update()
+	}
+	if leader.Pos().X < 1000 || core.Distance(red.Pos(), leader.Pos()) > 200 {
+		t.Error("wrong value", leader.Pos().X, red.Pos().X, red.Pos().Y)
+	}
+
+	// leader destroyed
+	leader.Remove()
+	for i := 0; i < 10; i++ {
+		Follow(red, leader, 150)
+		w.Update()
+	}
+	if red.Moving() {
+		t.Error("wrong value")
+	}
+}
+
+func TestEscort(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	leader, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponNone)
+	leader.SetPosition(core.NewPosition(500, 500), core.East)
+	w.AddTank(leader)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(500, 600), core.North)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponArtillery)
+	blue.SetPosition(core.NewPosition(1200, 500), core.West)
+	w.AddTank(blue)
+
+	// test nil
+	var nilEscort *Escort
+	nilEscort.Update(red)
+	NewEscort(leader, 100).Update(nil)
+
+	// escort: no attacker
+	e := NewEscort(leader, 150, core.RedTank)
+	for i := 0; i < 300; i++ {
+		e.Update(red)
+		w.Update()
+	}
+	if e.Attacker != nil || red.Moving() || blue.Health() != 100 {
+		t.Error("wrong value")
+	}
+
+	// attack the leader
+	for i := 0; i < 2000 && blue.Health() == 100; i++ {
+		GuardMode(blue, core.BlueTank)
+		e.Update(red)
+		w.Update()
+	}
+	if e.Attacker != blue || blue.Health() == 100 {
+		t.Error("wrong value", e.Attacker, blue.Health())
+	}
+}
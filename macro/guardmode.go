@@ -6,30 +6,50 @@ import (
 
 // GuardMode makes the tank wait and attack anything that approaches.
 // Cannons can change their angle but cannot move.
-func GuardMode(t *core.Tank, filter ...string) {
+// The target is chosen by the strategy of the tank (see SelectTarget).
+// Returns core.OrderDone if there are no more targets in range.
+func GuardMode(t *core.Tank, filter ...string) core.OrderState {
 	if t == nil || t.Weapon() == nil || t.Weapon().Type() == core.WeaponNone {
-		return // EXIT
+		return core.OrderFailed // EXIT
 	}
 
 	// macro
 	list := core.PossibleTargets(t, filter...)
-	if len(list) > 0 {
+	if len(list) == 0 {
+		report(t, core.PhaseGuarding, nil)
+		return core.OrderDone // EXIT
+	}
+	target, ok := SelectTarget(t, list)
+	if ok {
+		report(t, core.PhaseAttacking, target.Tank)
+		Attack(t, target)
+	} else {
+		report(t, core.PhaseGuarding, nil)
+	}
+	return core.OrderRunning
+}
+
+// Attack fires at the target.
+// Cannons rotate first if necessary.
+func Attack(t *core.Tank, target core.Target) {
+	if t == nil || target.Tank == nil || t.Weapon() == nil {
+		return // EXIT
+	}
 
-		// fire at targets
-		if t.Weapon().AnyFireAngle() {
-			// Artillery
-			t.FireAt(list[0].Tank.Pos())
+	// fire at target
+	if t.Weapon().AnyFireAngle() {
+		// Artillery
+		t.FireAt(target.Tank.Pos())
 
+	} else {
+		// Cannon
+		rs := RotationsToTarget(t, target.RelativeAngle)
+		if rs < 0 {
+			t.Left()
+		} else if rs > 0 {
+			t.Right()
 		} else {
-			// Cannon
-			rs := RotationsToTarget(t, list[0].RelativeAngle)
-			if rs < 0 {
-				t.Left()
-			} else if rs > 0 {
-				t.Right()
-			} else {
-				t.FireAt(list[0].Tank.Pos())
-			}
+			t.FireAt(target.Tank.Pos())
 		}
 	}
 }
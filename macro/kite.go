@@ -0,0 +1,142 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"strings"
+)
+
+// Kite keeps the tank at its weapon range from the nearest enemy whose range is shorter.
+// The tank retreats to the edge of its weapon range while the enemy can reach it or while reloading.
+// It stops to fire only when the enemy can't reach it.
+// Without such an enemy, the tank behaves like GuardMode.
+func Kite(t *core.Tank, filter ...string) {
+	if t == nil || t.Weapon() == nil || t.Weapon().Type() == core.WeaponNone {
+		return // EXIT
+	}
+
+	// find the nearest enemy with a shorter range
+	threat := nearestShorterRange(t, filter...)
+	if threat == nil {
+		if t.Moving() {
+			t.Stop()
+		}
+		GuardMode(t, filter...)
+		return // EXIT
+	}
+
+	// distances
+	dist := int(core.Distance(t.Pos(), threat.Pos()))
+	danger := WeaponReach(threat)                   // the enemy can hit this tank
+	engage := t.Weapon().Range() + core.BlockRadius // see core.CloseTargets
+	_, status := t.Status()
+
+	// macro
+	if dist <= danger {
+		report(t, core.PhaseRetreating, threat)
+		kiteMove(t, threat.Pos(), -1) // retreat
+	} else if dist >= engage {
+		report(t, core.PhaseApproaching, threat)
+		kiteMove(t, threat.Pos(), +1) // approach
+	} else if (t.Moving() || status == core.StatusReloading) && dist < engage-core.BlockRadius {
+		report(t, core.PhaseRetreating, threat)
+		kiteMove(t, threat.Pos(), -1) // keep retreating to the edge of the weapon range
+	} else {
+		if t.Moving() {
+			t.Stop() // prepare for fire
+		}
+		GuardMode(t, filter...)
+	}
+}
+
+// kiteMove moves the tank toward (dir > 0) or away from (dir < 0) the given position.
+// The tank drives backward if it faces the wrong way.
+func kiteMove(t *core.Tank, pos core.Position, dir int) {
+	// way is blocked: try another direction
+	unblock(t)
+
+	// heading
+	heading := core.RelativeAngle(t.Pos(), pos)
+	if dir < 0 {
+		heading = retreatHeading(t, (heading+180)%360)
+	}
+
+	// move
+	rs := RotationsToTarget(t, heading)
+	if rs >= -1 && rs <= 1 {
+		// forward
+		if t.Command() != 1 {
+			t.Forward()
+		}
+		turn(t, rs)
+	} else if rs <= -3 || rs >= 3 {
+		// backward
+		if t.Command() != -1 {
+			t.Backward()
+		}
+		turn(t, RotationsToTarget(t, (heading+180)%360))
+	} else {
+		// sideways: turn first
+		turn(t, rs)
+	}
+}
+
+// retreatHeading returns the heading closest to the given one that doesn't lead out of the world.
+// This allows the tank to slide along the world borders.
+func retreatHeading(t *core.Tank, heading int) int {
+	if t.World() == nil {
+		return heading
+	}
+	heading = (heading + 22) / 45 * 45 % 360 // tanks can only drive in 45° steps
+	for _, d := range []int{0, -45, 45, -90, 90} {
+		h := (heading + d + 360) % 360
+		p := core.CalcPosFromAngle(t.Pos(), h, core.BlockSize)
+		if !core.CheckBorders(p, core.BlockRadius, t.World().ScreenWidth(), t.World().ScreenHeight()) {
+			return h
+		}
+	}
+	return heading
+}
+
+// turn rotates the tank one step in the given direction.
+// see RotationsToTarget
+func turn(t *core.Tank, rs int) {
+	if rs < 0 {
+		t.Left()
+	} else if rs > 0 {
+		t.Right()
+	}
+}
+
+// nearestShorterRange returns the nearest enemy whose weapon range is shorter than the range of the tank.
+// Objects whose owner begins with a filter string are ignored (see core.CloseTargets).
+func nearestShorterRange(t *core.Tank, filter ...string) *core.Tank {
+	var threat *core.Tank
+	var threatDist float64
+
+	if t.World() == nil {
+		return nil
+	}
+	for _, ot := range t.World().Tanks() {
+		if ot == nil || ot == t || ot.Weapon() == nil || ot.Weapon().Type() == core.WeaponNone {
+			continue // no threat
+		}
+		if ot.Weapon().Range() >= t.Weapon().Range() || isFiltered(ot, filter...) {
+			continue // no target for kiting
+		}
+		if d := core.Distance(t.Pos(), ot.Pos()); threat == nil || d < threatDist {
+			threat = ot
+			threatDist = d
+		}
+	}
+	return threat
+}
+
+// isFiltered returns true if the owner of the tank begins with a filter string.
+func isFiltered(t *core.Tank, filter ...string) bool {
+	for _, f := range filter {
+		if len(f) > 0 && strings.HasPrefix(t.Owner(), f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestKite(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponArtillery)
+	red.SetPosition(core.NewPosition(600, 500), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 40, core.WeaponCannon)
+	blue.SetPosition(core.NewPosition(1200, 500), core.West)
+	w.AddTank(blue)
+
+	// test
+	for i := 0; i < 2000; i++ {
+		Kite(nil) // test nil
+		Kite(red, core.RedTank)
+		AttackMove(blue, core.BlueTank)
+		w.Update()
+
+		// the cannon must never reach the artillery
+		if d := int(core.Distance(red.Pos(), blue.Pos())); d <= WeaponReach(blue)-core.BlockRadius {
+			t.Fatal("wrong value", i, d)
+		}
+	}
+
+	// check
+	if red.Health() != 100 || red.Pos().X == 600 {
+		t.Error("wrong value", red.Health(), red.Pos().X)
+	}
+}
+
+func TestKite_NoThreat(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world: same range -> GuardMode
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(600, 500), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponRockets)
+	blue.SetPosition(core.NewPosition(800, 500), core.West)
+	w.AddTank(blue)
+
+	// test
+	for i := 0; i < 600; i++ {
+		Kite(red, core.RedTank)
+		w.Update()
+	}
+
+	// check
+	if red.Pos().X != 600 || blue.Health() == 100 {
+		t.Error("wrong value", red.Pos().X, blue.Health())
+	}
+}
+
+func TestWeaponReach(t *testing.T) {
+	if WeaponReach(nil) != 0 {
+		t.Error("wrong value")
+	}
+	nt, _ := core.NewTank(nil, core.RedTank, 5, 25, core.WeaponCannon)
+	if r := WeaponReach(nt); r != nt.Weapon().Range()+core.BallRadius+core.BlockRadius {
+		t.Error("wrong value", r)
+	}
+	nt, _ = core.NewTank(nil, core.RedTank, 5, 25, core.WeaponArtillery)
+	if r := WeaponReach(nt); r != nt.Weapon().Range()+nt.Weapon().AoERadius()+core.BlockRadius {
+		t.Error("wrong value", r)
+	}
+}
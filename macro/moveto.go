@@ -4,9 +4,10 @@ import "github.com/SchnorcherSepp/TankWars/core"
 
 // MoveTo uses Forward(), Left() and Right() to reach the given position.
 // If the tank becomes Blocked(), the algorithm will be paused and must be reset manually with Forward().
-func MoveTo(t *core.Tank, to core.Position) {
+// Returns core.OrderDone if the position is reached and core.OrderFailed if the tank is blocked.
+func MoveTo(t *core.Tank, to core.Position) core.OrderState {
 	if t == nil {
-		return // EXIT
+		return core.OrderFailed // EXIT
 	}
 
 	// tank position
@@ -19,10 +20,12 @@ func MoveTo(t *core.Tank, to core.Position) {
 			t.Forward()
 		}
 	} else {
+		report(t, core.PhaseArrived, nil)
 		if t.Moving() {
 			t.Stop()
-			return // EXIT!!
+			return core.OrderRunning // EXIT!!
 		}
+		return core.OrderDone // EXIT
 	}
 
 	// direction left/right
@@ -34,4 +37,12 @@ func MoveTo(t *core.Tank, to core.Position) {
 	if r > 0 {
 		t.Right()
 	}
+
+	// blocked
+	if t.Blocked() {
+		report(t, core.PhaseBlocked, nil)
+		return core.OrderFailed
+	}
+	report(t, core.PhaseMoving, nil)
+	return core.OrderRunning
 }
@@ -16,16 +16,19 @@ func TestMoveTo(t *testing.T) {
 	w.AddTank(rock)
 
 	// test nil
-	MoveTo(nil, core.Position{})
+	if MoveTo(nil, core.Position{}) != core.OrderFailed {
+		t.Error("wrong value")
+	}
 
 	// move
+	state := core.OrderRunning
 	for i := 0; i < 110; i++ {
-		MoveTo(nt, core.NewPosition(700, 700))
+		state = MoveTo(nt, core.NewPosition(700, 700))
 		w.Update()
 	}
 
 	// check
-	if nt.Blocked() != true || nt.Pos().X < 520 || nt.Pos().Y < 520 {
+	if state != core.OrderFailed || nt.Blocked() != true || nt.Pos().X < 520 || nt.Pos().Y < 520 {
 		t.Error("wrong value", nt.Blocked(), nt.Pos().X, nt.Pos().Y)
 	}
 
@@ -35,12 +38,12 @@ func TestMoveTo(t *testing.T) {
 	w.Update()
 
 	for i := 0; i < 100; i++ {
-		MoveTo(nt, core.NewPosition(700, 700))
+		state = MoveTo(nt, core.NewPosition(700, 700))
 		w.Update()
 	}
 
 	// check
-	if nt.Blocked() != false || nt.Pos().X < 670 || nt.Pos().Y < 670 {
+	if state != core.OrderDone || nt.Blocked() != false || nt.Pos().X < 670 || nt.Pos().Y < 670 {
 		t.Error("wrong value", nt.Blocked(), nt.Pos().X, nt.Pos().Y)
 	}
 }
@@ -0,0 +1,50 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// Patrol moves the tank along the waypoints in a loop.
+// If the tank encounters an enemy, it stops and opens fire (see GuardMode).
+// If the enemy is destroyed, the tank continues its patrol.
+type Patrol struct {
+	Waypoints []core.Position // positions in the order they are visited
+	Index     int             // current waypoint
+	Filter    []string        // see core.PossibleTargets
+}
+
+// NewPatrol returns a new patrol starting with the first waypoint.
+func NewPatrol(waypoints []core.Position, filter ...string) *Patrol {
+	return &Patrol{
+		Waypoints: waypoints,
+		Filter:    filter,
+	}
+}
+
+// Update is the macro function (see core.Tank.SetMacro).
+func (p *Patrol) Update(t *core.Tank) {
+	if t == nil || p == nil || len(p.Waypoints) == 0 {
+		return // EXIT
+	}
+
+	// engage
+	if t.Weapon() != nil && t.Weapon().Type() != core.WeaponNone && len(core.PossibleTargets(t, p.Filter...)) > 0 {
+		t.Stop()
+		GuardMode(t, p.Filter...)
+		return // EXIT
+	}
+
+	// next waypoint
+	p.Index %= len(p.Waypoints)
+	if core.Distance(t.Pos(), p.Waypoints[p.Index]) <= core.BlockRadius {
+		p.Index = (p.Index + 1) % len(p.Waypoints)
+	}
+	s := t.MacroState()
+	s.Waypoint = p.Index
+	t.SetMacroState(s)
+
+	// move
+	unblock(t)
+	MoveTo(t, p.Waypoints[p.Index])
+	report(t, core.PhaseMoving, nil)
+}
@@ -0,0 +1,59 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestPatrol(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(red)
+
+	// test nil
+	var nilPatrol *Patrol
+	nilPatrol.Update(red)
+	NewPatrol(nil).Update(red)
+	NewPatrol([]core.Position{{}}).Update(nil)
+
+	// patrol
+	p := NewPatrol([]core.Position{core.NewPosition(300, 300), core.NewPosition(700, 300)}, core.RedTank)
+	visited := map[int]bool{}
+	for i := 0; i < 2000; i++ {
+		p.Update(red)
+		w.Update()
+		if core.Distance(red.Pos(), p.Waypoints[1]) <= core.BlockRadius {
+			visited[1] = true
+		}
+		if visited[1] && core.Distance(red.Pos(), p.Waypoints[0]) <= core.BlockRadius {
+			visited[0] = true
+		}
+	}
+	if !visited[0] || !visited[1] {
+		t.Error("wrong value", visited)
+	}
+	if s := red.MacroState(); s.Phase != core.PhaseMoving || s.Waypoint != p.Index {
+		t.Error("wrong value", s)
+	}
+
+	// engage
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponNone)
+	blue.SetPosition(core.NewPosition(red.Pos().X, red.Pos().Y+200), core.East)
+	w.AddTank(blue)
+	attacking := false
+	for i := 0; i < 600; i++ {
+		p.Update(red)
+		w.Update()
+		if s := red.MacroState(); s.Phase == core.PhaseAttacking && s.TargetID == blue.ID() {
+			attacking = true
+		}
+	}
+	if blue.Health() == 100 || !attacking {
+		t.Error("wrong value", blue.Health(), attacking)
+	}
+}
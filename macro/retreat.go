@@ -0,0 +1,48 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// Retreat monitors the health of the tank and calls the combat macro as long as the tank is healthy.
+// Below the threshold, the tank disengages and returns to its home base (see core.World.HomeBase).
+// It stays there until it is fully repaired (see core.BaseRepairRadius); damaged tanks above the threshold keep fighting.
+// Without a home base, the combat macro is always called.
+func Retreat(t *core.Tank, threshold int, combat func(t *core.Tank)) {
+	if t == nil || t.World() == nil {
+		return // EXIT
+	}
+
+	// find home base
+	base := t.World().HomeBase(t.Owner())
+	if base == nil {
+		if combat != nil {
+			combat(t)
+		}
+		return // EXIT
+	}
+
+	// check health (a triggered retreat lasts until the tank is fully repaired)
+	s := t.MacroState()
+	retreated := (s.Phase == core.PhaseRetreating || s.Phase == core.PhaseRepairing) && s.TargetID == base.ID()
+	if t.Health() >= threshold && (!retreated || t.Health() >= core.TankMaxHealth) {
+		if combat != nil {
+			combat(t) // healthy
+		}
+		return // EXIT
+	}
+
+	// stay at the base
+	if core.IsCollided(t.Pos(), 0, base.Pos(), core.BaseRepairRadius-core.BlockRadius) {
+		report(t, core.PhaseRepairing, base)
+		if t.Moving() {
+			t.Stop()
+		}
+		return // EXIT
+	}
+
+	// return to base
+	unblock(t)
+	MoveTo(t, base.Pos())
+	report(t, core.PhaseRetreating, base)
+}
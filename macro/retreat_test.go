@@ -0,0 +1,97 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestRetreat(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	base, _ := core.NewTank(w, core.RedBase, 5, 15, core.WeaponNone)
+	base.SetPosition(core.NewPosition(200, 200), core.North)
+	w.AddTank(base)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(900, 200), core.East)
+	w.AddTank(red)
+
+	// test nil
+	Retreat(nil, 50, nil)
+
+	// healthy: call combat macro
+	var calls int
+	combat := func(t *core.Tank) {
+		calls++
+		t.Forward()
+	}
+	for i := 0; i < 30; i++ {
+		Retreat(red, 50, combat)
+		w.Update()
+	}
+	if calls != 30 || red.Pos().X <= 900 {
+		t.Error("wrong value", calls, red.Pos().X)
+	}
+
+	// damaged: return to base
+	red.Hit(60 + red.Armor())
+	calls = 0
+	for i := 0; i < 900; i++ {
+		Retreat(red, 50, combat)
+		w.Update()
+	}
+	if calls != 0 || core.Distance(red.Pos(), base.Pos()) > core.BaseRepairRadius || red.Moving() {
+		t.Error("wrong value", calls, red.Pos().X, red.Pos().Y)
+	}
+	if red.Health() <= 40 || red.Health() >= core.TankMaxHealth {
+		t.Error("wrong value", red.Health())
+	}
+
+	// stay until fully repaired
+	for i := 0; i < 60*core.BaseRepairDelay && calls == 0; i++ {
+		Retreat(red, 50, combat)
+		w.Update()
+	}
+	if calls == 0 || red.Health() != core.TankMaxHealth {
+		t.Error("wrong value", calls, red.Health())
+	}
+
+	// no base: call combat macro
+	base.Remove()
+	red.Hit(60 + red.Armor())
+	calls = 0
+	Retreat(red, 50, combat)
+	if calls != 1 {
+		t.Error("wrong value", calls)
+	}
+}
+
+func TestRetreat_notTriggered(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	base, _ := core.NewTank(w, core.RedBase, 5, 15, core.WeaponNone)
+	base.SetPosition(core.NewPosition(200, 200), core.North)
+	w.AddTank(base)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(300, 200), core.East)
+	w.AddTank(red)
+
+	// damaged above the threshold within the repair radius: keep fighting
+	red.Hit(20 + red.Armor())
+	var calls int
+	combat := func(t *core.Tank) {
+		calls++
+		report(t, core.PhaseAttacking, nil)
+	}
+	for i := 0; i < 10; i++ {
+		Retreat(red, 50, combat)
+		w.Update()
+	}
+	if calls != 10 || red.Health() >= core.TankMaxHealth {
+		t.Error("wrong value", calls, red.Health())
+	}
+}
@@ -0,0 +1,153 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// SelectTarget picks a target from the list according to the strategy of the tank (see core.Tank.Strategy).
+// The list is expected as returned by core.PossibleTargets().
+// Returns false if there is no target worth shooting.
+func SelectTarget(t *core.Tank, list []core.Target) (core.Target, bool) {
+	if t == nil || len(list) == 0 {
+		return core.Target{}, false // EXIT
+	}
+
+	// select index
+	i := 0
+	switch t.Strategy() {
+	case core.StrategyClosest:
+		i = selectClosest(list)
+	case core.StrategyLowestHealth:
+		i = selectLowestHealth(list)
+	case core.StrategyHighestThreat:
+		i = selectHighestThreat(t, list)
+	case core.StrategyBuildingsFirst:
+		i = selectBuildingsFirst(list)
+	case core.StrategyFocusFire:
+		i = selectFocusFire(t, list)
+	case core.StrategyNoOverkill:
+		i = selectNoOverkill(t, list)
+	default:
+		i = 0 // StrategyRotation: the list is already sorted
+	}
+
+	// return
+	if i < 0 {
+		return core.Target{}, false
+	}
+	return list[i], true
+}
+
+// IsStrategy returns true if the name is a known target strategy (see core.StrategyRotation, ...).
+func IsStrategy(name string) bool {
+	switch name {
+	case core.StrategyRotation, core.StrategyClosest, core.StrategyLowestHealth, core.StrategyHighestThreat,
+		core.StrategyBuildingsFirst, core.StrategyFocusFire, core.StrategyNoOverkill:
+		return true
+	default:
+		return false
+	}
+}
+
+//--------------------------------------------------------------------------------------------------------------------//
+
+// selectClosest returns the index of the closest target.
+func selectClosest(list []core.Target) int {
+	best := 0
+	for i, ot := range list {
+		if ot.Distance < list[best].Distance {
+			best = i
+		}
+	}
+	return best
+}
+
+// selectLowestHealth returns the index of the target with the lowest health.
+func selectLowestHealth(list []core.Target) int {
+	best := 0
+	for i, ot := range list {
+		if ot.Tank.Health() < list[best].Tank.Health() {
+			best = i
+		}
+	}
+	return best
+}
+
+// selectHighestThreat returns the index of the target that deals the most damage per second to this tank.
+func selectHighestThreat(t *core.Tank, list []core.Target) int {
+	best := 0
+	bestThreat := -1.0
+	for i, ot := range list {
+		threat := 0.0
+		if w := ot.Tank.Weapon(); w != nil && w.Type() != core.WeaponNone && w.ReloadTime() > 0 {
+			threat = float64(t.EffectiveDamage(w.Damage())) / float64(w.ReloadTime())
+		}
+		if threat > bestThreat {
+			best = i
+			bestThreat = threat
+		}
+	}
+	return best
+}
+
+// selectBuildingsFirst returns the index of the first target without weapon (bases).
+// If there is no building, the first target is returned.
+func selectBuildingsFirst(list []core.Target) int {
+	for i, ot := range list {
+		if w := ot.Tank.Weapon(); w == nil || w.Type() == core.WeaponNone {
+			return i
+		}
+	}
+	return 0
+}
+
+// selectFocusFire returns the index of the target with the most allied projectiles in flight.
+// If no target is under fire, the first target is returned.
+func selectFocusFire(t *core.Tank, list []core.Target) int {
+	best := 0
+	bestCount := 0
+	for i, ot := range list {
+		count := 0
+		for _, p := range alliedProjectiles(t) {
+			if AimedAt(p, ot.Tank.Pos()) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best = i
+			bestCount = count
+		}
+	}
+	return best
+}
+
+// selectNoOverkill returns the index of the first target that survives all allied projectiles in flight.
+// Returns -1 if all targets are already doomed.
+func selectNoOverkill(t *core.Tank, list []core.Target) int {
+	for i, ot := range list {
+		pending := 0
+		for _, p := range alliedProjectiles(t) {
+			if AimedAt(p, ot.Tank.Pos()) {
+				pending += ot.Tank.EffectiveDamage(p.Damage())
+			}
+		}
+		if pending < ot.Tank.Health() {
+			return i
+		}
+	}
+	return -1
+}
+
+// alliedProjectiles returns all flying projectiles fired by the owner of this tank.
+func alliedProjectiles(t *core.Tank) []*core.Projectile {
+	list := make([]*core.Projectile, 0, 8)
+	if t == nil || t.World() == nil {
+		return list
+	}
+	for _, p := range t.World().Projectiles() {
+		if p != nil && !p.Exploded() && p.Parent() != nil && p.Parent().Owner() == t.Owner() {
+			list = append(list, p)
+		}
+	}
+	return list
+}
@@ -0,0 +1,98 @@
+package macro
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestSelectTarget(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(100, 100)
+	me, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponArtillery)
+	me.SetPosition(core.NewPosition(500, 500), core.North)
+	w.AddTank(me)
+
+	near, _ := core.NewTank(w, core.BlueTank, 5, 15, core.WeaponRockets) // closest
+	near.SetPosition(core.NewPosition(600, 500), core.North)
+	w.AddTank(near)
+	weak, _ := core.NewTank(w, core.BlueTank, 5, 15, core.WeaponCannon) // lowest health
+	weak.SetPosition(core.NewPosition(500, 700), core.North)
+	weak.Hit(50)
+	w.AddTank(weak)
+	strong, _ := core.NewTank(w, core.BlueTank, 5, 70, core.WeaponCannon) // highest threat
+	strong.SetPosition(core.NewPosition(200, 500), core.North)
+	w.AddTank(strong)
+	base, _ := core.NewTank(w, core.BlueBase, 5, 15, core.WeaponNone) // building
+	base.SetPosition(core.NewPosition(500, 150), core.North)
+	w.AddTank(base)
+
+	list := core.PossibleTargets(me)
+	if len(list) != 4 {
+		t.Fatal("wrong value", len(list))
+	}
+
+	// test nil
+	if _, ok := SelectTarget(nil, list); ok {
+		t.Error("wrong value")
+	}
+	if _, ok := SelectTarget(me, nil); ok {
+		t.Error("wrong value")
+	}
+
+	// test strategies
+	for _, tc := range []struct {
+		strategy string
+		want     *core.Tank
+	}{
+		{"", list[0].Tank},
+		{core.StrategyRotation, list[0].Tank},
+		{core.StrategyClosest, near},
+		{core.StrategyLowestHealth, weak},
+		{core.StrategyHighestThreat, strong},
+		{core.StrategyBuildingsFirst, base},
+		{core.StrategyFocusFire, list[0].Tank}, // nothing in flight
+		{core.StrategyNoOverkill, list[0].Tank},
+	} {
+		me.SetStrategy(tc.strategy)
+		if target, ok := SelectTarget(me, list); !ok || target.Tank != tc.want {
+			t.Error("wrong value", tc.strategy, ok, target.Tank.Owner())
+		}
+	}
+
+	// fire at the weak tank
+	w.UpdateN(int(me.Weapon().PreparationTime()))
+	if ok, txt := me.FireAt(weak.Pos()); !ok {
+		t.Fatal("wrong value", txt)
+	}
+
+	// focus fire
+	me.SetStrategy(core.StrategyFocusFire)
+	if target, ok := SelectTarget(me, list); !ok || target.Tank != weak {
+		t.Error("wrong value", ok, target.Tank.ID())
+	}
+
+	// no overkill: the weak tank will survive the artillery shell
+	me.SetStrategy(core.StrategyNoOverkill)
+	if target, ok := SelectTarget(me, []core.Target{{Tank: weak}}); !ok || target.Tank != weak {
+		t.Error("wrong value", ok)
+	}
+	weak.Hit(weak.Health() - 1 + weak.Armor()) // 1 HP left
+	if _, ok := SelectTarget(me, []core.Target{{Tank: weak}}); ok {
+		t.Error("wrong value")
+	}
+	if target, ok := SelectTarget(me, []core.Target{{Tank: weak}, {Tank: near}}); !ok || target.Tank != near {
+		t.Error("wrong value", ok)
+	}
+}
+
+func TestIsStrategy(t *testing.T) {
+	if !IsStrategy(core.StrategyClosest) || !IsStrategy(core.StrategyNoOverkill) {
+		t.Error("wrong value")
+	}
+	if IsStrategy("") || IsStrategy("wrong") {
+		t.Error("wrong value")
+	}
+}
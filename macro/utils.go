@@ -25,3 +25,99 @@ func RotationsToTarget(t *core.Tank, relativeAngle int) int {
 	// compare absolut values
 	return int(math.Round(float64(ra) / 45))
 }
+
+// unblock turns a blocked tank randomly to the left or right and drives forward.
+func unblock(t *core.Tank) {
+	if !t.Blocked() {
+		return // EXIT
+	}
+	if t.World().Random(2) == 1 {
+		t.Left()
+	} else {
+		t.Right()
+	}
+	t.Forward()
+}
+
+// report sets the phase and the target of the macro state (see core.Tank.SetMacroState).
+// The waypoint is kept.
+func report(t *core.Tank, phase string, target *core.Tank) {
+	s := t.MacroState()
+	s.Phase = phase
+	s.TargetID = ""
+	if target != nil {
+		s.TargetID = target.ID()
+	}
+	t.SetMacroState(s)
+}
+
+// AimedAt returns true if the projectile will hit an object at the given position.
+// Projectiles without collision explode at EndPos(); cannon balls fly straight ahead until the max distance.
+func AimedAt(p *core.Projectile, pos core.Position) bool {
+	if p == nil || p.Exploded() {
+		return false
+	}
+
+	// Cannon: check the remaining flight path
+	if p.Collision() {
+		return DistanceToLine(p.Pos(), p.EndPos(), pos) < core.BlockRadius+core.BallRadius
+	}
+
+	// Artillery: check the blast radius
+	return core.IsCollided(p.EndPos(), blastRadius(p.AoERadius()), pos, core.BlockRadius)
+}
+
+// blastRadius returns the aoe radius of a projectile, but at least core.BallRadius (see core.Projectile.Explode).
+func blastRadius(aoeRadius int) int {
+	if aoeRadius < core.BallRadius {
+		return core.BallRadius
+	}
+	return aoeRadius
+}
+
+// DistanceToLine returns the distance between pos and the line segment from a to b.
+func DistanceToLine(a, b, pos core.Position) float64 {
+	dx := b.Xf - a.Xf
+	dy := b.Yf - a.Yf
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return core.Distance(a, pos) // a == b
+	}
+
+	// project pos onto the line and clamp to the segment
+	f := ((pos.Xf-a.Xf)*dx + (pos.Yf-a.Yf)*dy) / l2
+	f = math.Max(0, math.Min(1, f))
+
+	// distance to the projection
+	return core.Length(a.Xf+f*dx-pos.Xf, a.Yf+f*dy-pos.Yf)
+}
+
+// WeaponReach returns the max. distance between two tanks at which the weapon of the tank can do damage.
+// It is the weapon range plus the blast radius (or the projectile radius) and the radius of the other tank.
+func WeaponReach(t *core.Tank) int {
+	if t == nil || t.Weapon() == nil || t.Weapon().Type() == core.WeaponNone {
+		return 0
+	}
+
+	return t.Weapon().Range() + blastRadius(t.Weapon().AoERadius()) + core.BlockRadius
+}
+
+// Filters returns the filters for core.PossibleTargets() that exclude own units, the own base and all rocks.
+func Filters(t *core.Tank) []string {
+	filters := make([]string, 0, 6)
+
+	if t != nil {
+		owner := t.Owner() // RedTank or BlueTank
+
+		// own tanks and own base
+		filters = append(filters, owner)
+		filters = append(filters, owner+"_base")
+
+		// all rocks
+		filters = append(filters, core.NeutralRock)
+		filters = append(filters, core.RedRock)
+		filters = append(filters, core.BlueRock)
+	}
+
+	return filters
+}
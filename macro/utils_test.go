@@ -81,3 +81,39 @@ func TestRotationsToTarget(t *testing.T) {
 		}
 	}
 }
+
+func TestAimedAt(t *testing.T) {
+	pos := core.NewPosition(500, 500)
+
+	// test nil
+	if AimedAt(nil, pos) {
+		t.Error("wrong value")
+	}
+
+	// artillery explodes at the end
+	p := core.NewProjectile(nil, nil, core.NewPosition(500, 100), core.South, 400, 300, 10, 50, false)
+	if !AimedAt(p, pos) || AimedAt(p, core.NewPosition(500, 300)) {
+		t.Error("wrong value")
+	}
+
+	// cannon balls hit anything on the path
+	p = core.NewProjectile(nil, nil, core.NewPosition(500, 100), core.South, 338, 600, 10, 0, true)
+	if !AimedAt(p, core.NewPosition(510, 300)) || AimedAt(p, pos) || AimedAt(p, core.NewPosition(600, 300)) {
+		t.Error("wrong value")
+	}
+}
+
+func TestDistanceToLine(t *testing.T) {
+	a := core.NewPosition(0, 0)
+	b := core.NewPosition(100, 0)
+
+	if d := DistanceToLine(a, b, core.NewPosition(50, 30)); d != 30 {
+		t.Error("wrong value", d)
+	}
+	if d := DistanceToLine(a, b, core.NewPosition(-40, 30)); d != 50 {
+		t.Error("wrong value", d)
+	}
+	if d := DistanceToLine(a, a, core.NewPosition(30, 40)); d != 50 {
+		t.Error("wrong value", d)
+	}
+}
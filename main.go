@@ -5,11 +5,12 @@ import (
 	"github.com/SchnorcherSepp/TankWars/core"
 	"github.com/SchnorcherSepp/TankWars/examples/goai"
 	"github.com/SchnorcherSepp/TankWars/gui"
-	"github.com/SchnorcherSepp/TankWars/macro"
 	"github.com/SchnorcherSepp/TankWars/maps"
 	"github.com/SchnorcherSepp/TankWars/remote"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 const version = "1.0b"
@@ -22,6 +23,17 @@ func main() {
 	srvMode := flag.Bool("server", false, "start server and wait for two player")
 	srvAddr := flag.String("host", "127.0.0.1", "server ip")
 	srvPort := flag.String("port", "3333", "server port")
+	httpPort := flag.String("http", "", "gateway port for websocket and json-rpc clients (disabled if empty)")
+	origins := flag.String("origins", "", "comma separated origins of other web pages that can use the websocket gateway")
+	redToken := flag.String("red-token", "", "reserve player red for clients with this token (see -blue-token)")
+	blueToken := flag.String("blue-token", "", "reserve player blue for clients with this token (see -red-token)")
+	adminToken := flag.String("admin-token", "", "clients with this token can join as admin (disabled if empty)")
+	pause := flag.Bool("pause", false, "pause the game while a player is disconnected")
+	forfeit := flag.Duration("forfeit", 0, "max. time to reconnect before a player loses the game (disabled if 0)")
+	countdown := flag.Duration("countdown", 0, "countdown before the game starts")
+	games := flag.Int("games", 1, "number of games of a best-of-n series (the map is loaded again after each game)")
+	swap := flag.Bool("swap", false, "swap the sides of red and blue after each game of the series")
+	nextGame := flag.Duration("next-game", 5*time.Second, "time between two games of the series")
 
 	flag.Parse()
 
@@ -38,7 +50,9 @@ func main() {
 
 	// AI MODE
 	if *aiMode {
-		goai.MyAI(*srvAddr, *srvPort) // blocking
+		if err := goai.MyAI(*srvAddr, *srvPort); err != nil { // blocking
+			log.Fatal(err)
+		}
 		os.Exit(0)
 	}
 
@@ -47,21 +61,38 @@ func main() {
 
 	// run server?
 	if *srvMode {
-		go remote.RunServer(*srvAddr, *srvPort, w)
-	}
-
-	// map 'field', 'fortress' or 'random'
-	switch *mapName {
-	case "field":
-		maps.InitOpenField(w)
-	case "fortress":
-		maps.InitFortress(w)
-	case "random":
-		maps.InitRandomWorld(w, 1337, func(t *core.Tank) { macro.AttackMove(t) })
-	case "test":
-		maps.InitTest(w)
-	default:
-		log.Fatal("unknown map")
+		s := remote.NewServer(w)
+		if *redToken != "" || *blueToken != "" {
+			if err := s.SetTokens(*redToken, *blueToken); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *adminToken != "" {
+			if err := s.SetAdminToken(*adminToken); err != nil {
+				log.Fatal(err)
+			}
+		}
+		s.SetDisconnectPolicy(*pause, *forfeit)
+		s.SetCountdown(*countdown)
+		if err := s.SetSeries(*games, *swap, *nextGame); err != nil {
+			log.Fatal(err)
+		}
+		if err := s.LoadMap(*mapName); err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			log.Fatal(s.ListenTCP(*srvAddr, *srvPort))
+		}()
+		if *httpPort != "" {
+			if *origins != "" {
+				s.SetOrigins(strings.Split(*origins, ",")...)
+			}
+			go func() {
+				log.Fatal(s.ListenHTTP(*srvAddr, *httpPort))
+			}()
+		}
+	} else if err := maps.Load(w, *mapName); err != nil {
+		log.Fatal(err) // map 'field', 'fortress' or 'random'
 	}
 
 	// run gui (blocking)
@@ -0,0 +1,37 @@
+package maps
+
+import (
+	"errors"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/macro"
+)
+
+// ErrUnknownMap is returned by Load for unknown map names.
+var ErrUnknownMap = errors.New("unknown map")
+
+// Load removes all objects of the world (see core.World.Reset) and builds the map with the name
+// ('field', 'fortress', 'random' or 'test').
+func Load(w *core.World, name string) error {
+	return LoadSeed(w, name, 1337)
+}
+
+// LoadSeed is like Load, but the random map is generated with the seed (see InitRandomWorld).
+func LoadSeed(w *core.World, name string, seed int64) error {
+	var create func(w *core.World)
+	switch name {
+	case "field":
+		create = func(w *core.World) { InitOpenField(w) }
+	case "fortress":
+		create = func(w *core.World) { InitFortress(w) }
+	case "random":
+		create = func(w *core.World) { InitRandomWorld(w, seed, func(t *core.Tank) { macro.AttackMove(t) }) }
+	case "test":
+		create = func(w *core.World) { InitTest(w) }
+	default:
+		return ErrUnknownMap
+	}
+
+	w.Reset()
+	create(w)
+	return nil
+}
@@ -0,0 +1,124 @@
+package remote
+
+import (
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/maps"
+	"strconv"
+)
+
+// MaxSpeed is the max. speed multiplier of the command SetSpeed.
+const MaxSpeed = 100
+
+// admin runs an admin command and returns the response (see adminCommands).
+func (s *Server) admin(args []string) string {
+	a1, a2, _, _, _, _ := saveArgs(args)
+	switch args[0] {
+	case "Pause":
+		s.setPaused(true)
+		return "ok"
+	case "Resume":
+		s.setPaused(false)
+		return "ok"
+	case "SetSpeed":
+		return s.setSpeed(a1)
+	case "Restart":
+		s.mux.Lock()
+		name := s.mapName
+		s.mux.Unlock()
+		if name == "" {
+			return fail(CodeNotFound, NameMapNotFound, "no map loaded")
+		}
+		return response(s.LoadMap(name))
+	case "LoadMap":
+		return response(s.LoadMap(a1))
+	case "SetCash":
+		return s.setCash(a1, a2)
+	default:
+		return failErr(ErrInvalidCommand)
+	}
+}
+
+// LoadMap removes all objects of the world and builds the map (see maps.Load).
+// The connections and the player slots are kept; a running game starts again after the countdown (see SetCountdown).
+func (s *Server) LoadMap(name string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.loadMap(name)
+}
+
+// loadMap is LoadMap (call with lock).
+func (s *Server) loadMap(name string) error {
+	s.world.Lock()
+	err := maps.Load(s.world, name)
+	if err == nil {
+		s.hub.history.reset()
+		if s.started {
+			s.world.Start(s.countdownTicks())
+		}
+	}
+	s.world.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.mapName = name
+	s.over = false
+	s.freeze()
+	fmt.Printf("LOAD MAP %s\n", name)
+	return nil
+}
+
+// setPaused pauses or resumes the game (see Pause and Resume).
+func (s *Server) setPaused(paused bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.paused = paused
+	s.freeze()
+	if paused {
+		fmt.Printf("PAUSE GAME\n")
+	} else {
+		fmt.Printf("RESUME GAME\n")
+	}
+}
+
+// setSpeed sets the speed multiplier of the game loop (see core.World.SetSpeed).
+func (s *Server) setSpeed(speed string) string {
+	n, err := strconv.Atoi(speed)
+	if err != nil {
+		return failArg("speed", err.Error())
+	}
+	if n < 1 || n > MaxSpeed {
+		return failArg("speed", fmt.Sprintf("expected a number between 1 and %d", MaxSpeed))
+	}
+	s.world.Lock()
+	s.world.SetSpeed(n)
+	s.world.Unlock()
+	return "ok"
+}
+
+// setCash overwrites the cash of both players.
+func (s *Server) setCash(red, blue string) string {
+	r, err := strconv.Atoi(red)
+	if err != nil || r < 0 {
+		return failArg("red", "expected a number >= 0")
+	}
+	b, err := strconv.Atoi(blue)
+	if err != nil || b < 0 {
+		return failArg("blue", "expected a number >= 0")
+	}
+
+	s.world.Lock()
+	s.world.SetCash(r, b)
+	s.world.Unlock()
+	return "ok"
+}
+
+// response is a helper function and converts an error to a response.
+func response(err error) string {
+	if err != nil {
+		return failErr(err)
+	}
+	return "ok"
+}
@@ -0,0 +1,176 @@
+package remote
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Guest is the player of a connection that hasn't joined yet (see SetTokens and command Join).
+const Guest = "guest"
+
+// Observer is the slot of all observers (see command Join).
+const Observer = "observer"
+
+// Admin is the player of admin connections (see SetAdminToken and command Join).
+const Admin = "admin"
+
+// roles of the players (see Role)
+const (
+	RolePlayer   = "player"   // controls the tanks of red or blue
+	RoleObserver = "observer" // watches the game (incl. guests)
+	RoleAdmin    = "admin"    // controls the server (see adminCommands)
+)
+
+// guestCommands are the commands of clients that haven't joined yet (see access).
+var guestCommands = map[string]bool{
+	"Join":       true,
+	"Reconnect":  true,
+	"ErrorCodes": true,
+	"RequestIDs": true,
+}
+
+// adminCommands are the commands of the role admin (see access).
+var adminCommands = map[string]bool{
+	"Exit":     true,
+	"Pause":    true,
+	"Resume":   true,
+	"SetSpeed": true,
+	"Restart":  true,
+	"LoadMap":  true,
+	"SetCash":  true,
+}
+
+// Role returns the role of a player (RolePlayer, RoleObserver or RoleAdmin).
+func Role(owner string) string {
+	switch owner {
+	case core.RedTank, core.BlueTank:
+		return RolePlayer
+	case Admin:
+		return RoleAdmin
+	default:
+		return RoleObserver
+	}
+}
+
+// SetTokens reserves the player slots: clients must join with the token of the slot (see command Join).
+// Without tokens, the first client becomes red and the second blue (see Server).
+func (s *Server) SetTokens(red, blue string) error {
+	if red == "" || blue == "" {
+		return errors.New("empty token")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.tokens = map[string]string{core.RedTank: red, core.BlueTank: blue}
+	return nil
+}
+
+// SetAdminToken enables the role admin: clients join with this token as admin (see command Join).
+func (s *Server) SetAdminToken(token string) error {
+	if token == "" {
+		return errors.New("empty token")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.adminToken = token
+	return nil
+}
+
+// joinSlot is the command Join: the connection takes the player slot (red or blue with token or a free slot without
+// tokens), becomes an observer or an admin (with token). Observers can join as admin, too.
+// It returns the new player of the connection and the response ("ok {session}" for players, see Reconnect).
+func (s *Server) joinSlot(owner, slot, token string, conn io.Closer) (string, string) {
+	if owner != Guest && (slot != Admin || Role(owner) != RoleObserver) {
+		return owner, fail(CodeConflict, NameAlreadyJoined, "already joined")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	switch slot {
+	case Observer:
+		s.observers++
+		owner = fmt.Sprintf("observer-%d", s.observers)
+
+	case core.RedTank, core.BlueTank:
+		// without tokens, only free slots can be claimed (the TCP connections get them in order, see join)
+		if s.tokens == nil && s.sessions[slot] != nil {
+			return owner, fail(CodeConflict, NameSlotTaken, "slot is taken")
+		}
+		if s.tokens != nil && subtle.ConstantTimeCompare([]byte(token), []byte(s.tokens[slot])) != 1 {
+			return owner, fail(CodeUnauthorized, NameInvalidToken, "invalid token")
+		}
+		if sess := s.sessions[slot]; sess != nil && sess.conn != nil {
+			return owner, fail(CodeConflict, NameSlotTaken, "slot is taken")
+		} else if sess != nil && sess.forfeited {
+			return owner, fail(CodeConflict, NameForfeited, "forfeited")
+		}
+		session := s.claim(slot, conn)
+		fmt.Printf("guest joined as %s\n", slot)
+
+		// START GAME with both players!!
+		if !s.started && s.connected() {
+			s.start()
+		}
+		return slot, "ok " + session
+
+	case Admin:
+		if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			return owner, fail(CodeUnauthorized, NameInvalidToken, "invalid token")
+		}
+		fmt.Printf("%s joined as %s\n", owner, Admin)
+		return Admin, "ok"
+
+	default:
+		return owner, failArg("player", "expected red, blue, observer or admin")
+	}
+
+	fmt.Printf("guest joined as %s\n", owner)
+	return owner, "ok"
+}
+
+// httpPlayer returns the player of a HTTP request: the player (or admin) of the token
+// ("Authorization: Bearer {token}") or HttpObserver without token. Returns false if the token is invalid.
+func (s *Server) httpPlayer(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return HttpObserver, true
+	}
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", false
+	}
+	token := []byte(strings.TrimPrefix(auth, "Bearer "))
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for player, t := range s.tokens {
+		if subtle.ConstantTimeCompare(token, []byte(t)) == 1 {
+			return player, true
+		}
+	}
+	if s.adminToken != "" && subtle.ConstantTimeCompare(token, []byte(s.adminToken)) == 1 {
+		return Admin, true
+	}
+	return "", false
+}
+
+// access returns an error response if the player isn't allowed to run the command.
+// Returns an empty string if the command is allowed.
+func access(owner, cmd string) string {
+	if owner == Guest && !guestCommands[cmd] {
+		return fail(CodeUnauthorized, NameNotJoined, "not joined")
+	}
+	if adminCommands[cmd] && Role(owner) != RoleAdmin {
+		return fail(CodeForbidden, NameForbidden, "forbidden")
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_SetTokens(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	s := NewServer(w)
+	if err := s.SetTokens("", "b"); err == nil {
+		t.Error("wrong value", err)
+	}
+	if err := s.SetTokens("r", "b"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.ListenTCP("localhost", "3339")
+	}()
+	time.Sleep(400 * time.Millisecond) // wait for server
+
+	red, _ := NewTcpClient("localhost", "3339")
+	blue, _ := NewTcpClient("localhost", "3339")
+	obs, err := NewTcpClient("localhost", "3339")
+	if err != nil || red == nil || blue == nil {
+		t.Fatal(err)
+	}
+
+	// guests
+	if me, err := red.MyName(); me != "err: not joined" || err == nil {
+		t.Error("wrong value", me, err)
+	}
+	if err := red.Join(core.RedTank, "b"); err == nil || err.Error() != "invalid token" {
+		t.Error("wrong value", err)
+	}
+	if err := red.Join("green", "r"); err == nil {
+		t.Error("wrong value", err)
+	}
+
+	// join
+	if err := red.Join(core.RedTank, "r"); err != nil {
+		t.Error("wrong value", err)
+	}
+	if me, err := red.MyName(); me != core.RedTank || err != nil {
+		t.Error("wrong value", me, err)
+	}
+	if err := red.Join(core.BlueTank, "b"); err == nil || err.Error() != "already joined" {
+		t.Error("wrong value", err)
+	}
+	if err := obs.Join(core.RedTank, "r"); err == nil || err.Error() != "slot is taken" {
+		t.Error("wrong value", err)
+	}
+	if err := obs.Join(Observer, ""); err != nil {
+		t.Error("wrong value", err)
+	}
+	if me, _ := obs.MyName(); me != "observer-1" {
+		t.Error("wrong value", me)
+	}
+	if !frozen(w) {
+		t.Error("game started without blue")
+	}
+	if err := blue.Join(core.BlueTank, "b"); err != nil {
+		t.Error("wrong value", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if frozen(w) {
+		t.Error("game not started")
+	}
+
+	// the slot is free again
+	_ = red.Close()
+	time.Sleep(50 * time.Millisecond)
+	red, _ = NewTcpClient("localhost", "3339")
+	if err := red.Join(core.RedTank, "r"); err != nil {
+		t.Error("wrong value", err)
+	}
+	_ = red.Close()
+	_ = blue.Close()
+	_ = obs.Close()
+}
+
+func TestServer_httpPlayer(t *testing.T) {
+	resources.MuteSound = true
+
+	s := NewServer(core.NewWorld(100, 200))
+	_ = s.SetTokens("r", "b")
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	post := func(auth, body string) (int, string) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/rpc", strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	tests := []struct {
+		auth string
+		code int
+		want string
+	}{
+		{"", http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"http"}`},
+		{"Bearer r", http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"red"}`},
+		{"Bearer b", http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"blue"}`},
+		{"Bearer x", http.StatusUnauthorized, "invalid token\n"},
+		{"Basic r", http.StatusUnauthorized, "invalid token\n"},
+	}
+	for _, tt := range tests {
+		if code, got := post(tt.auth, `{"jsonrpc":"2.0","id":1,"method":"MyName"}`); code != tt.code || got != tt.want {
+			t.Error("wrong value", tt.auth, code, got)
+		}
+	}
+
+	// websocket guests
+	ws := dialWebSocket(t, srv.URL)
+	defer ws.Close()
+	if got := ws.read(t); got != `{"jsonrpc":"2.0","method":"welcome","params":{"player":"guest"}}` {
+		t.Error("wrong value", got)
+	}
+	ws.write(t, `{"jsonrpc":"2.0","id":1,"method":"GameStatus"}`)
+	if got := ws.read(t); got != `{"jsonrpc":"2.0","id":1,"error":{"code":401,"message":"not joined","data":"not_joined"}}` {
+		t.Error("wrong value", got)
+	}
+	ws.write(t, `{"jsonrpc":"2.0","id":2,"method":"Join","params":["blue","b"]}`)
+	if got := ws.read(t); !strings.HasPrefix(got, `{"jsonrpc":"2.0","id":2,"result":"ok `) {
+		t.Error("wrong value", got)
+	}
+	ws.write(t, `{"jsonrpc":"2.0","id":3,"method":"MyName"}`)
+	if got := ws.read(t); got != `{"jsonrpc":"2.0","id":3,"result":"blue"}` {
+		t.Error("wrong value", got)
+	}
+}
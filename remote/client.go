@@ -2,223 +2,753 @@ package remote
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"github.com/SchnorcherSepp/TankWars/script"
 	"net"
 	"net/textproto"
 	"strings"
 	"sync"
+	"time"
 )
 
+// DialTimeout is the max. time to connect to a server (see NewTcpClient).
+var DialTimeout = 5 * time.Second
+
 //---------------- TcpClient -----------------------------------------------------------------------------------------//
 
 // TcpClient is an API to access a server
 // and to remote control player tanks.
 type TcpClient struct {
-	conn *net.TCPConn
-	tp   *textproto.Reader
-	mux  *sync.Mutex
+	addr    string
+	conn    net.Conn
+	tp      *textproto.Reader
+	mux     *sync.Mutex
+	hooks   Hooks
+	pipe    *pipeline               // request ids (see Pipeline)
+	join    string                  // the command Join, repeated after a reconnect without session (see Join)
+	session string                  // the session token of the player slot (see Reconnect)
+	codes   bool                    // the error codes are enabled (see ErrorCodes)
+	local   func(cmd string) string // handler of a client without connection (see NewLocalClient)
 }
 
-// NewTcpClient init a TcpClient
-func NewTcpClient(host, port string) *TcpClient {
-
-	// address
-	tcpAddr, err := net.ResolveTCPAddr("tcp", host+":"+port)
-	if err != nil {
-		log.Fatalf("NewTcpClient: %v\n", err)
-	}
+// Hooks are called by the TcpClient on connection errors (see SetHooks).
+// They are called while the client is locked and must not use the client.
+type Hooks struct {
+	Reconnect    bool                 // redial the server after a connection error and repeat the command once
+	OnDisconnect func(err error)      // called after a connection error
+	OnReconnect  func(welcome string) // called after a successful reconnect with the first line of the server
+}
 
-	// connection
-	conn, err := net.DialTCP("tcp", nil, tcpAddr)
-	if err != nil {
-		log.Fatalf("NewTcpClient: %v\n", err)
-	}
+// NewTcpClient connects to a server (see DialTimeout).
+func NewTcpClient(host, port string) (*TcpClient, error) {
+	return DialTcpClient(context.Background(), host, port)
+}
 
-	// config client
+// DialTcpClient connects to a server.
+// The context can cancel the connection attempt; DialTimeout is used in any case.
+func DialTcpClient(ctx context.Context, host, port string) (*TcpClient, error) {
 	tc := &TcpClient{
-		conn: conn,
-		tp:   textproto.NewReader(bufio.NewReader(conn)),
+		addr: net.JoinHostPort(host, port),
 		mux:  new(sync.Mutex),
 	}
 
-	// read first line after connect
-	first, err := tc.tp.ReadLine()
-	if err != nil {
-		log.Fatal(err)
+	if _, err := tc.dial(ctx); err != nil {
+		return nil, err
 	}
-	println(first)
 
 	// return client
-	return tc
+	return tc, nil
+}
+
+// NewLocalClient returns a client without connection that sends all commands to the handler,
+// e.g. remote.Execute for matches in the same process (see package arena).
+// The errors of Execute always contain the error codes. Stream mode, pipelining and the hooks are not supported.
+func NewLocalClient(handler func(cmd string) string) *TcpClient {
+	return &TcpClient{
+		mux:   new(sync.Mutex),
+		local: handler,
+	}
+}
+
+// SetHooks sets the hooks that are called on connection errors.
+func (tc *TcpClient) SetHooks(h Hooks) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	tc.hooks = h
+}
+
+// Close closes the connection without killing the server (see Exit).
+func (tc *TcpClient) Close() error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.local != nil {
+		tc.local = nil
+		return nil
+	}
+	if tc.conn == nil {
+		return ErrClosed
+	}
+	err := tc.conn.Close()
+	tc.conn, tc.tp, tc.pipe = nil, nil, nil
+	return err
 }
 
 //---------------- GETTER --------------------------------------------------------------------------------------------//
 
 // MyName returns the active player of this connection (RedTank or BlueTank)
-func (tc *TcpClient) MyName() string {
+func (tc *TcpClient) MyName() (string, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	resp := command(tc, "MyName")
+	return resp, ParseError(resp)
+}
+
+// GameStatus returns all world data.
+func (tc *TcpClient) GameStatus() (*JsonWorld, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, "MyName")
+	w := new(JsonWorld)
+	return w, decode(tc, "GameStatus", w)
 }
 
-// GameStatus returns a json with all world data.
-func (tc *TcpClient) GameStatus() string {
+// GameResult returns the game state and the winner and reason of a finished game.
+func (tc *TcpClient) GameResult() (JsonGameResult, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, "GameStatus")
+	var r JsonGameResult
+	return r, decode(tc, "GameResult", &r)
 }
 
-// TankStatus returns a json with all data of a requested tank.
-func (tc *TcpClient) TankStatus(tankID string) string {
+// Series returns the score of the series (see Server.SetSeries).
+// Call MyName after a new game, because the sides can be swapped.
+func (tc *TcpClient) Series() (JsonSeries, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("TankStatus %s", tankID))
+	var js JsonSeries
+	return js, decode(tc, "Series", &js)
+}
+
+// GameStatusSince updates the world with the changes since its iteration (see JsonWorld.Merge).
+// An empty world (without tanks) requests the full world.
+func (tc *TcpClient) GameStatusSince(w *JsonWorld) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	cmd := "GameStatusSince"
+	if len(w.Tanks) > 0 {
+		cmd = fmt.Sprintf("GameStatusSince %d %d", w.Iteration, w.Epoch)
+	}
+	d := new(JsonWorldDelta)
+	if err := decode(tc, cmd, d); err != nil {
+		return err
+	}
+	w.Merge(d)
+	return nil
+}
+
+// Events returns all game events with a sequence number greater than sinceSeq (see JsonEvent).
+func (tc *TcpClient) Events(sinceSeq uint64) (JsonEvents, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	var es JsonEvents
+	return es, decode(tc, fmt.Sprintf("Events %d", sinceSeq), &es)
+}
+
+// TankStatus returns all data of a requested tank.
+func (tc *TcpClient) TankStatus(tankID string) (JsonTank, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	var t JsonTank
+	return t, decode(tc, fmt.Sprintf("TankStatus %s", tankID), &t)
 }
 
 // CloseTargets returns all objects in the world that are theoretical in weapon range.
 // The weapon type is irrelevant (WeaponCannon or WeaponArtillery) and the angle of the tank is ignored.
 // The list is sorted by distance (from the closest to the farthest).
-func (tc *TcpClient) CloseTargets(tankID, f1, f2, f3, f4, f5 string) string {
+func (tc *TcpClient) CloseTargets(tankID, f1, f2, f3, f4, f5 string) (JsonTargets, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("CloseTargets %s %s %s %s %s %s", tankID, f1, f2, f3, f4, f5))
+	var ts JsonTargets
+	return ts, decode(tc, fmt.Sprintf("CloseTargets %s %s %s %s %s %s", tankID, f1, f2, f3, f4, f5), &ts)
 }
 
 // PossibleTargets extends CloseTargets.
 // It only returns objects that can actually be attacked,depending on the weapon type.
 // However, it may be necessary for the battle tank to change its angle.
 // The list is sorted by the rotation required to reach the target.
-func (tc *TcpClient) PossibleTargets(tankID, f1, f2, f3, f4, f5 string) string {
+func (tc *TcpClient) PossibleTargets(tankID, f1, f2, f3, f4, f5 string) (JsonTargets, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("PossibleTargets %s %s %s %s %s %s", tankID, f1, f2, f3, f4, f5))
+	var ts JsonTargets
+	return ts, decode(tc, fmt.Sprintf("PossibleTargets %s %s %s %s %s %s", tankID, f1, f2, f3, f4, f5), &ts)
 }
 
 //---------------- SETTER --------------------------------------------------------------------------------------------//
 
-// Exit kills the server (for tests only).
-func (tc *TcpClient) Exit() string {
+// Join takes a reserved player slot (red or blue) with the token or joins as observer (empty token).
+// The client reclaims the slot (or joins again) after a reconnect (see Hooks).
+func (tc *TcpClient) Join(player, token string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	cmd := strings.TrimSpace("Join " + player + " " + token)
+	resp := command(tc, cmd)
+	if err := ParseError(resp); err != nil {
+		return err
+	}
+	tc.join = cmd
+	if session := strings.TrimSpace(strings.TrimPrefix(resp, "ok")); session != "" {
+		tc.session = session
+	}
+	return nil
+}
+
+// Reconnect reclaims the player slot of a session token after a disconnect (see Session).
+// The client does this automatically after a reconnect (see Hooks).
+func (tc *TcpClient) Reconnect(session string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, "Exit")
+	if err := exec(tc, "Reconnect "+session); err != nil {
+		return err
+	}
+	tc.session = session
+	return nil
+}
+
+// ErrorCodes enables or disables the error codes of this connection (see command ErrorCodes).
+// With error codes, the errors of the server contain the code and name (see Error); they are disabled by default.
+func (tc *TcpClient) ErrorCodes(on bool) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	mode := "off"
+	if on {
+		mode = "on"
+	}
+	if err := exec(tc, "ErrorCodes "+mode); err != nil {
+		return err
+	}
+	tc.codes = on
+	return nil
+}
+
+// Session returns the session token of the player slot (empty for observers).
+func (tc *TcpClient) Session() string {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return tc.session
+}
+
+// Exit kills the server (admin only, see Join).
+// The server sends no response; the connection is closed.
+func (tc *TcpClient) Exit() error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.conn == nil {
+		return ErrClosed
+	}
+	_, err := tc.conn.Write([]byte("Exit\r\n"))
+	_ = tc.conn.Close()
+	tc.conn, tc.tp = nil, nil
+	return err
 }
 
 // BuyTank buy a new tank and place it near the home base.
-func (tc *TcpClient) BuyTank(armor, damage int, weapon string) string {
+// Returns the id of the new tank.
+func (tc *TcpClient) BuyTank(armor, damage int, weapon string) (string, error) {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("BuyTank %d %d %s", armor, damage, weapon))
+	resp := command(tc, fmt.Sprintf("BuyTank %d %d %s", armor, damage, weapon))
+	if err := ParseError(resp); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(resp, "ok")), nil
 }
 
 // Fire creates a new projectile.
 // The attributes fireAngle and distance determine the direction and distance of the shot.
 // Cannons can fire in vehicle angle only.
 // The distance is limited by the weapon range.
-func (tc *TcpClient) Fire(tankID string, angle, distance int) string {
+func (tc *TcpClient) Fire(tankID string, angle, distance int) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Fire %s %d %d", tankID, angle, distance))
+	return exec(tc, fmt.Sprintf("Fire %s %d %d", tankID, angle, distance))
 }
 
 // FireAt is a wrapper for Fire() and convert the position to fireAngle and distance.
-func (tc *TcpClient) FireAt(tankID string, x, y int) string {
+func (tc *TcpClient) FireAt(tankID string, x, y int) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("FireAt %s %d %d", tankID, x, y))
+	return exec(tc, fmt.Sprintf("FireAt %s %d %d", tankID, x, y))
 }
 
 // Forward send the tank forward.
-func (tc *TcpClient) Forward(tankID string) string {
+func (tc *TcpClient) Forward(tankID string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Forward %s", tankID))
+	return exec(tc, fmt.Sprintf("Forward %s", tankID))
 }
 
 // Backward send the tank back.
-func (tc *TcpClient) Backward(tankID string) string {
+func (tc *TcpClient) Backward(tankID string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Backward %s", tankID))
+	return exec(tc, fmt.Sprintf("Backward %s", tankID))
 }
 
 // Stop the movement.
 // Weapons can only build up when the tank is stationary
-func (tc *TcpClient) Stop(tankID string) string {
+func (tc *TcpClient) Stop(tankID string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Stop %s", tankID))
+	return exec(tc, fmt.Sprintf("Stop %s", tankID))
 }
 
 // Left turn the tank direction 45° left.
-func (tc *TcpClient) Left(tankID string) string {
+func (tc *TcpClient) Left(tankID string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Left %s", tankID))
+	return exec(tc, fmt.Sprintf("Left %s", tankID))
 }
 
 // Right turn the tank direction 45° right.
-func (tc *TcpClient) Right(tankID string) string {
+func (tc *TcpClient) Right(tankID string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("Right %s", tankID))
+	return exec(tc, fmt.Sprintf("Right %s", tankID))
 }
 
 // SetMacroMoveTo sets a special macro with a position that is called with every update.
-func (tc *TcpClient) SetMacroMoveTo(tankID string, x, y int) string {
+func (tc *TcpClient) SetMacroMoveTo(tankID string, x, y int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetMacroMoveTo %s %d %d", tankID, x, y))
+}
+
+// SetMacroRetreat sets a special macro that calls the combat macro as long as the tank is healthy.
+// Below the health threshold, the tank returns to its home base and stays there until it is fully repaired.
+func (tc *TcpClient) SetMacroRetreat(tankID string, threshold int, combat string) error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("SetMacroMoveTo %s %d %d", tankID, x, y))
+	return exec(tc, fmt.Sprintf("SetMacroRetreat %s %d %s", tankID, threshold, combat))
 }
 
 // SetMacro sets a macro that is called with every update.
-func (tc *TcpClient) SetMacro(tankID, macro string) string {
+func (tc *TcpClient) SetMacro(tankID, macro string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetMacro %s %s", tankID, macro))
+}
+
+// Patrol moves the tank along the waypoints (x1, y1, x2, y2, ...) in a loop.
+// If the tank encounters an enemy, it stops and opens fire.
+func (tc *TcpClient) Patrol(tankID string, coords ...int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	cmd := fmt.Sprintf("Patrol %s", tankID)
+	for _, c := range coords {
+		cmd += fmt.Sprintf(" %d", c)
+	}
+	return exec(tc, cmd)
+}
+
+// Follow keeps the tank within the given distance of the leader.
+func (tc *TcpClient) Follow(tankID, leaderID string, distance int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("Follow %s %s %d", tankID, leaderID, distance))
+}
+
+// Escort follows the leader and engages anything attacking the leader.
+func (tc *TcpClient) Escort(tankID, leaderID string, distance int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("Escort %s %s %d", tankID, leaderID, distance))
+}
+
+// QueueOrder appends an order to the order queue of the tank (e.g. "MoveTo", "300", "400").
+// The orders are executed in sequence. An active macro is removed.
+func (tc *TcpClient) QueueOrder(tankID string, order ...string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, strings.TrimSpace(fmt.Sprintf("QueueOrder %s %s", tankID, strings.Join(order, " "))))
+}
+
+// ClearOrders removes all queued orders of the tank.
+func (tc *TcpClient) ClearOrders(tankID string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("ClearOrders %s", tankID))
+}
+
+// SetBehavior sets a behavior tree as macro.
+// The tree is expected as JSON (see behavior.Spec).
+func (tc *TcpClient) SetBehavior(tankID, tree string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetBehavior %s %s", tankID, strings.Join(strings.Fields(tree), " ")))
+}
+
+// DefineMacro uploads a macro script (see package script).
+// The script is sent in one line, so comments are removed.
+func (tc *TcpClient) DefineMacro(name, src string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("DefineMacro %s %s", name, script.OneLine(src)))
+}
+
+// SetStrategy sets the target selection strategy used by the macros of a tank.
+func (tc *TcpClient) SetStrategy(tankID, strategy string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetStrategy %s %s", tankID, strategy))
+}
+
+// Batch runs all commands in the same iteration and returns the responses in the same order.
+// The error is only set if the whole batch fails; use ParseError for the single responses.
+func (tc *TcpClient) Batch(cmds ...string) ([]string, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	b, err := json.Marshal(append([]string{}, cmds...)) // never null
+	if err != nil {
+		return nil, err
+	}
+	var results []string
+	return results, decode(tc, "Batch "+string(b), &results)
+}
+
+// Subscribe switches the connection to stream mode: the server pushes the world after every n iterations.
+// The channel is closed when the connection fails or is closed (see Close).
+// No other commands can be sent with this client; use a second client for the commands.
+func (tc *TcpClient) Subscribe(everyNTicks int) (<-chan *JsonWorld, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.pipe != nil {
+		return nil, ErrPipeline
+	}
+	if err := exec(tc, fmt.Sprintf("Subscribe %d", everyNTicks)); err != nil {
+		return nil, err
+	}
+
+	// receive
+	ch := make(chan *JsonWorld)
+	tp := tc.tp
+	tc.tp = nil // stream mode
+	go func() {
+		defer close(ch)
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return // EXIT
+			}
+			w := new(JsonWorld)
+			if err := json.Unmarshal([]byte(line), w); err == nil {
+				ch <- w
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// SubscribeEvents switches the connection to stream mode: the server pushes the new game events after every update.
+// The channel is closed when the connection fails or is closed (see Close).
+// No other commands can be sent with this client; use a second client for the commands.
+func (tc *TcpClient) SubscribeEvents() (<-chan JsonEvent, error) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.pipe != nil {
+		return nil, ErrPipeline
+	}
+	if err := exec(tc, "SubscribeEvents"); err != nil {
+		return nil, err
+	}
+
+	// receive
+	ch := make(chan JsonEvent)
+	tp := tc.tp
+	tc.tp = nil // stream mode
+	go func() {
+		defer close(ch)
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return // EXIT
+			}
+			var es JsonEvents
+			if err := json.Unmarshal([]byte(line), &es); err == nil {
+				for _, e := range es {
+					ch <- e
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Pipeline enables request ids on this connection (see command RequestIDs),
+// so CommandAsync can send many commands without waiting for the responses.
+// All other methods still wait for their response (and block CommandAsync in the meantime).
+// Stream mode isn't possible with pipelining and a reconnect (see Hooks) disables it.
+func (tc *TcpClient) Pipeline() error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.pipe != nil {
+		return nil // already enabled
+	}
+	if err := exec(tc, "RequestIDs on"); err != nil {
+		return err
+	}
+	tc.pipe = newPipeline(tc.conn, tc.tp)
+	return nil
+}
+
+// CommandAsync sends a raw command line and returns the channel of the response (see Pipeline).
+func (tc *TcpClient) CommandAsync(cmd string) <-chan Response {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	if tc.pipe == nil {
+		ch := make(chan Response, 1)
+		ch <- Response{Err: ErrNoPipeline}
+		return ch
+	}
+	return tc.pipe.send(cleanCommand(cmd))
+}
+
+// Command sends a raw command line to the server and returns the response (see README).
+func (tc *TcpClient) Command(cmd string) string {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return command(tc, cmd)
+}
+
+//---------------- ADMIN ---------------------------------------------------------------------------------------------//
+
+// Pause freezes the world until Resume is called (admin only, see Join).
+func (tc *TcpClient) Pause() error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, "Pause")
+}
+
+// Resume continues the game after Pause (admin only, see Join).
+func (tc *TcpClient) Resume() error {
 	tc.mux.Lock()
 	defer tc.mux.Unlock()
 
-	return command(tc, fmt.Sprintf("SetMacro %s %s", tankID, macro))
+	return exec(tc, "Resume")
+}
+
+// SetSpeed sets the speed multiplier of the game (1 to MaxSpeed, admin only, see Join).
+func (tc *TcpClient) SetSpeed(speed int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetSpeed %d", speed))
+}
+
+// Restart reloads the map of the game (admin only, see Join).
+func (tc *TcpClient) Restart() error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, "Restart")
+}
+
+// LoadMap loads a new map ('field', 'fortress', 'random' or 'test', admin only, see Join).
+func (tc *TcpClient) LoadMap(name string) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, "LoadMap "+name)
+}
+
+// SetCash overwrites the cash of both players (admin only, see Join).
+func (tc *TcpClient) SetCash(red, blue int) error {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+
+	return exec(tc, fmt.Sprintf("SetCash %d %d", red, blue))
 }
 
 //---------------- HELPER --------------------------------------------------------------------------------------------//
 
-// command send the cmd to the server and return the response
+// dial connects to the server and returns the first line.
+func (tc *TcpClient) dial(ctx context.Context) (string, error) {
+	// connection
+	d := net.Dialer{Timeout: DialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", tc.addr)
+	if err != nil {
+		return "", fmt.Errorf("TcpClient: %v", err)
+	}
+
+	// read first line after connect
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	_ = conn.SetReadDeadline(time.Now().Add(DialTimeout))
+	first, err := tp.ReadLine()
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("TcpClient: %v", err)
+	}
+
+	tc.conn, tc.tp, tc.pipe = conn, tp, nil
+
+	// enable the error codes again (see ErrorCodes)
+	if tc.codes {
+		if _, err := roundTrip(tc, "ErrorCodes on"); err != nil {
+			_ = conn.Close()
+			tc.conn, tc.tp = nil, nil
+			return "", err
+		}
+	}
+
+	// reclaim the player slot (see Reconnect) or join again (see Join)
+	var rejoin string
+	if tc.session != "" {
+		rejoin = "Reconnect " + tc.session
+	} else if tc.join != "" {
+		rejoin = tc.join
+	} else if f := strings.Fields(first); len(f) == 4 && f[0] == "welcome" {
+		tc.session = f[3] // "welcome player {player} {session}"
+	}
+	if rejoin != "" {
+		resp, err := roundTrip(tc, rejoin)
+		if err == nil {
+			err = ParseError(resp)
+		}
+		if err != nil {
+			_ = conn.Close()
+			tc.conn, tc.tp = nil, nil
+			return "", err
+		}
+	}
+	return first, nil
+}
+
+// command send the cmd to the server and return the response.
+// Connection errors call the hooks and are returned as "err: TcpClient ...".
 func command(tc *TcpClient, cmd string) string {
+	if tc != nil && tc.local != nil {
+		return tc.local(cleanCommand(cmd))
+	}
 	if tc == nil || tc.conn == nil || tc.tp == nil {
-		return "err: TcpClient connection closed."
+		return "err: " + ErrClosed.Error()
+	}
+
+	// send command
+	cmd = cleanCommand(cmd)
+	resp, err := roundTrip(tc, cmd)
+	if err == nil {
+		return resp // server response
+	}
+
+	// connection error
+	_ = tc.conn.Close()
+	if tc.hooks.OnDisconnect != nil {
+		tc.hooks.OnDisconnect(err)
+	}
+	if !tc.hooks.Reconnect {
+		tc.conn, tc.tp, tc.pipe = nil, nil, nil
+		return "err: " + err.Error()
+	}
+
+	// reconnect and repeat
+	first, rErr := tc.dial(context.Background())
+	if rErr != nil {
+		tc.conn, tc.tp = nil, nil
+		return "err: " + rErr.Error()
+	}
+	if tc.hooks.OnReconnect != nil {
+		tc.hooks.OnReconnect(first)
+	}
+	resp, err = roundTrip(tc, cmd)
+	if err != nil {
+		return "err: " + err.Error()
 	}
+	return resp
+}
 
-	// remove protocol break
+// cleanCommand removes protocol breaks.
+func cleanCommand(cmd string) string {
 	cmd = strings.ReplaceAll(cmd, "\n", "")
 	cmd = strings.ReplaceAll(cmd, "\r", "")
 	cmd = strings.ReplaceAll(cmd, "  ", " ")
+	return cmd
+}
+
+// roundTrip writes the cmd and reads the response.
+func roundTrip(tc *TcpClient, cmd string) (string, error) {
+	// pipelining (see Pipeline)
+	if tc.pipe != nil {
+		r := <-tc.pipe.send(cmd)
+		return r.Line, r.Err
+	}
 
 	// send command
 	_, err := tc.conn.Write([]byte(fmt.Sprintf("%s\r\n", cmd)))
 	if err != nil {
-		return fmt.Sprintf("err: TcpClient write: %v", err)
+		return "", fmt.Errorf("TcpClient write: %v", err)
 	}
 
 	// read response
 	resp, err := tc.tp.ReadLine()
 	if err != nil {
-		return fmt.Sprintf("err: TcpClient read: %v", err)
+		return "", fmt.Errorf("TcpClient read: %v", err)
 	}
+	return resp, nil
+}
 
-	// return server response
-	return resp
+// exec sends the command and returns the error of the response (see ParseError).
+func exec(tc *TcpClient, cmd string) error {
+	return ParseError(command(tc, cmd))
+}
+
+// decode sends the command and parses the json response.
+func decode(tc *TcpClient, cmd string, v interface{}) error {
+	resp := command(tc, cmd)
+	if err := ParseError(resp); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(resp), v)
 }
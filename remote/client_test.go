@@ -1,11 +1,15 @@
 package remote
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/SchnorcherSepp/TankWars/core"
 	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"net"
+	"net/textproto"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -48,70 +52,149 @@ func Test_Server_Client(t *testing.T) {
 
 	// start server and init client
 	go RunServer("localhost", "3333", w)
-	time.Sleep(400 * time.Millisecond)          // wait for server
-	NewTcpClient("localhost", "3333")           // player red
-	client := NewTcpClient("localhost", "3333") // player blue
-	NewTcpClient("localhost", "3333")           // observer
+	time.Sleep(400 * time.Millisecond)                           // wait for server
+	if _, err := NewTcpClient("localhost", "3333"); err != nil { // player red
+		t.Fatal(err)
+	}
+	client, err := NewTcpClient("localhost", "3333") // player blue
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTcpClient("localhost", "3333"); err != nil { // observer
+		t.Fatal(err)
+	}
 
 	//---------------------
 
-	respGS := client.GameStatus()
+	respGS := client.Command("GameStatus")
 	if !strings.HasPrefix(respGS, "{\"gameSpeed\":") {
 		t.Error(respGS)
 	}
-	respTS := client.TankStatus("1236")
+	if jw, err := client.GameStatus(); err != nil || len(jw.Tanks) != 4 {
+		t.Error(jw, err)
+	}
+	respTS := client.Command("TankStatus 1236")
 	if !strings.HasPrefix(respTS, "{\"id\":\"") {
 		t.Error(respTS)
 	}
-	respCT := client.CloseTargets("1236", "", "", "", "", "")
+	if jt, err := client.TankStatus("1236"); err != nil || jt.ID != "1236" {
+		t.Error(jt, err)
+	}
+	if _, err := client.TankStatus("1"); err != ErrTankNotFound {
+		t.Error(err)
+	}
+	respCT := client.Command("CloseTargets 1236")
 	if !strings.HasPrefix(respCT, "[{\"tankID\":") {
 		t.Error(respCT)
 	}
-	respPT := client.PossibleTargets("1236", "", "", "", "", "")
+	if ts, err := client.CloseTargets("1236", "", "", "", "", ""); err != nil || len(ts) == 0 {
+		t.Error(ts, err)
+	}
+	respPT := client.Command("PossibleTargets 1236")
 	if !strings.HasPrefix(respPT, "[{\"tankID\":\"1238\",\"distance\":222,\"relativeAngle\":0}]") {
 		t.Error(respPT)
 	}
-	if resp := client.MyName(); resp != core.BlueTank {
-		t.Error(resp)
+	if ts, err := client.PossibleTargets("1236", "", "", "", "", ""); err != nil || len(ts) != 1 || ts[0].TankID != "1238" {
+		t.Error(ts, err)
 	}
-	if resp := client.Fire("1236", 30, 12); resp != "err: Preparing" {
-		t.Error(resp)
+	if resp, err := client.MyName(); resp != core.BlueTank || err != nil {
+		t.Error(resp, err)
 	}
-	if resp := client.FireAt("1236", 300, 200); resp != "err: Preparing" {
-		t.Error(resp)
+	if err := client.Fire("1236", 30, 12); err != (ErrNotReady{Status: core.StatusPreparing}) {
+		t.Error(err)
 	}
-	if resp := client.BuyTank(5, 70, core.WeaponCannon); resp != "err: not enough tank budget or unknown owner" {
-		t.Error(resp)
+	if err := client.FireAt("1236", 300, 200); err != (ErrNotReady{Status: core.StatusPreparing}) {
+		t.Error(err)
 	}
-	if resp := client.BuyTank(5, 70, core.WeaponCannon); resp != "err: not enough tank budget or unknown owner" {
-		t.Error(resp)
+	if id, err := client.BuyTank(5, 70, core.WeaponCannon); id != "" || err == nil || err.Error() != "not enough tank budget or unknown owner" {
+		t.Error(id, err)
 	}
-	if resp := client.Forward("1236"); resp != "ok" {
-		t.Error(resp)
+	if err := client.Forward("1235"); err != ErrNoAccess {
+		t.Error(err)
 	}
-	if resp := client.Backward("1236"); resp != "ok" {
-		t.Error(resp)
+	if err := client.Forward("1236"); err != nil {
+		t.Error(err)
+	}
+	if err := client.Backward("1236"); err != nil {
+		t.Error(err)
 	}
 	w.UpdateN(core.TankRotationDelay)
-	if resp := client.Left("1236"); resp != "ok" {
-		t.Error(resp)
+	if err := client.Left("1236"); err != nil {
+		t.Error(err)
+	}
+	if err := client.Right("1236"); err != (ErrNotReady{Status: core.StatusPreparing}) {
+		t.Error(err)
+	}
+	if err := client.Stop("1236"); err != nil {
+		t.Error(err)
+	}
+	if err := client.SetMacroMoveTo("1236", 11, 22); err != nil {
+		t.Error(err)
+	}
+	if err := client.SetMacro("1236", core.MacroGuardMode); err != nil {
+		t.Error(err)
+	}
+	if err := client.SetMacroRetreat("1236", 30, core.MacroGuardMode); err != nil {
+		t.Error(err)
+	}
+	if err := client.Patrol("1236", 10, 20, 30, 40); err != nil {
+		t.Error(err)
+	}
+	if err := client.Follow("1236", "1238", 50); err != nil {
+		t.Error(err)
 	}
-	if resp := client.Right("1236"); resp != "err: Preparing" {
-		t.Error(resp)
+	if err := client.Escort("1236", "1238", 50); err != nil {
+		t.Error(err)
+	}
+	if err := client.QueueOrder("1236", "MoveTo", "300", "400"); err != nil {
+		t.Error(err)
+	}
+	if err := client.ClearOrders("1236"); err != nil {
+		t.Error(err)
 	}
-	if resp := client.Stop("1236"); resp != "ok" {
-		t.Error(resp)
+	if err := client.SetBehavior("1236", `{"type": "sequence", "children": [
+		{"type": "condition", "name": "Ready"},
+		{"type": "action", "name": "FireAtTarget"}]}`); err != nil {
+		t.Error(err)
 	}
-	if resp := client.SetMacroMoveTo("1236", 11, 22); resp != "ok" {
-		t.Error(resp)
+	if err := client.DefineMacro("Hold", `# stop and fire
+		if targets() > 0 { stop(); fireattarget(); } # attack
+		else { guard(); }`); err != nil {
+		t.Error(err)
 	}
-	if resp := client.SetMacro("1236", core.MacroGuardMode); resp != "ok" {
-		t.Error(resp)
+	if err := client.SetMacro("1236", "Hold"); err != nil {
+		t.Error(err)
+	}
+	if err := client.SetStrategy("1236", core.StrategyClosest); err != nil {
+		t.Error(err)
 	}
 
 	// wrong command
-	if "err: invalid command" != command(client, "wrong") {
-		t.Error("wrong value")
+	if resp := command(client, "wrong"); resp != "err: invalid command" {
+		t.Error("wrong value", resp)
+	}
+	if err := client.ErrorCodes(true); err != nil || !client.codes {
+		t.Error("wrong value", err)
+	}
+	if resp := command(client, "wrong"); resp != "err 400 invalid_command invalid command" {
+		t.Error("wrong value", resp)
+	}
+	if err := client.ErrorCodes(false); err != nil || client.codes {
+		t.Error("wrong value", err)
+	}
+	if resp := command(client, "ErrorCodes nothing"); resp != "err: mode: expected on or off" {
+		t.Error("wrong value", resp)
+	}
+	if err := exec(client, "wrong"); err != ErrInvalidCommand {
+		t.Error(err)
+	}
+
+	// closed connection
+	if err := client.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := client.Stop("1236"); err != ErrClosed {
+		t.Error(err)
 	}
 
 	//--------------------------------
@@ -142,6 +225,236 @@ func Test_Server_Client(t *testing.T) {
 	fmt.Printf("\nPossibleTargets:\n%s\n", s)
 }
 
+func TestTcpClient_Subscribe(t *testing.T) {
+	resources.MuteSound = true
+
+	// start server and init client
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	go RunServer("localhost", "3336", w)
+	time.Sleep(400 * time.Millisecond) // wait for server
+	red, _ := NewTcpClient("localhost", "3336")
+	blue, _ := NewTcpClient("localhost", "3336")
+	sub, err := NewTcpClient("localhost", "3336")
+	if err != nil || red == nil || blue == nil {
+		t.Fatal(err)
+	}
+
+	// errors
+	if _, err := sub.Subscribe(0); err == nil || err.Error() != "everyNTicks: expected a number > 0" {
+		t.Error("wrong value", err)
+	}
+
+	// subscribe
+	ch, err := sub.Subscribe(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.MyName(); err != ErrClosed {
+		t.Error("stream mode", err)
+	}
+	time.Sleep(50 * time.Millisecond) // wait for subscription
+	w.UpdateN(6)
+	for _, want := range []uint64{2, 4, 6} {
+		select {
+		case jw := <-ch:
+			if jw.Iteration != want {
+				t.Error("wrong value", jw.Iteration, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	// close
+	_ = sub.Close()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("channel should be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// delta
+	jw := new(JsonWorld)
+	if err := red.GameStatusSince(jw); err != nil || jw.Iteration != 6 {
+		t.Error("wrong value", jw.Iteration, err)
+	}
+	w.UpdateN(3)
+	if err := red.GameStatusSince(jw); err != nil || !reflect.DeepEqual(*jw, NewJsonWorld(w)) {
+		t.Error("wrong merge", jw.Iteration, err)
+	}
+}
+
+func TestTcpClient_SubscribeEvents(t *testing.T) {
+	resources.MuteSound = true
+
+	// start server and init client
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	tank, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	tank.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(tank)
+	w.UpdateN(100) // weapon ready
+	go RunServer("localhost", "3337", w)
+	time.Sleep(400 * time.Millisecond) // wait for server
+	red, _ := NewTcpClient("localhost", "3337")
+	blue, _ := NewTcpClient("localhost", "3337")
+	sub, err := NewTcpClient("localhost", "3337")
+	if err != nil || red == nil || blue == nil {
+		t.Fatal(err)
+	}
+
+	// subscribe
+	ch, err := sub.SubscribeEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond) // wait for subscription
+	tank.Fire(core.East, 0)
+	w.Update()
+	for _, want := range []string{core.EventStarted, core.EventFired} {
+		select {
+		case e := <-ch:
+			if e.Type != want || (want == core.EventFired && (e.TankID != tank.ID() || e.Seq != 2)) {
+				t.Error("wrong value", e)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	// poll
+	if es, err := red.Events(0); err != nil || len(es) != 2 || es[0].Type != core.EventStarted || es[1].Type != core.EventFired {
+		t.Error("wrong value", es, err)
+	}
+	_ = sub.Close()
+}
+
+func TestTcpClient_Pipeline(t *testing.T) {
+	resources.MuteSound = true
+
+	// start server and init client
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	go RunServer("localhost", "3338", w)
+	time.Sleep(400 * time.Millisecond) // wait for server
+	red, _ := NewTcpClient("localhost", "3338")
+	blue, err := NewTcpClient("localhost", "3338")
+	if err != nil || red == nil {
+		t.Fatal(err)
+	}
+
+	// disabled
+	if r := <-red.CommandAsync("MyName"); r.Err != ErrNoPipeline {
+		t.Error("wrong value", r)
+	}
+
+	// async
+	if err := red.Pipeline(); err != nil {
+		t.Fatal(err)
+	}
+	chs := make([]<-chan Response, 0)
+	for i := 0; i < 50; i++ {
+		chs = append(chs, red.CommandAsync("MyName"))
+	}
+	chs = append(chs, red.CommandAsync("TankStatus x"))
+	for i, ch := range chs {
+		select {
+		case r := <-ch:
+			if r.Err != nil || (i < 50 && r.Line != core.RedTank) || (i == 50 && ParseError(r.Line) != ErrTankNotFound) {
+				t.Error("wrong value", i, r)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	// sync
+	if me, err := red.MyName(); err != nil || me != core.RedTank {
+		t.Error("wrong value", me, err)
+	}
+	if _, err := red.Subscribe(1); err != ErrPipeline {
+		t.Error("wrong value", err)
+	}
+
+	// protocol
+	if resp := blue.Command("RequestIDs x"); resp != "err: mode: expected on or off" {
+		t.Error("wrong value", resp)
+	}
+	if resp := blue.Command("RequestIDs on"); resp != "ok" {
+		t.Error("wrong value", resp)
+	}
+	if resp := blue.Command("a7 MyName"); resp != "a7 blue" {
+		t.Error("wrong value", resp)
+	}
+	if resp := blue.Command("a8"); resp != "a8 err: invalid command" {
+		t.Error("wrong value", resp)
+	}
+	if resp := blue.Command("a9 RequestIDs off"); resp != "a9 ok" {
+		t.Error("wrong value", resp)
+	}
+
+	// close
+	_ = red.Close()
+	if r := <-red.CommandAsync("MyName"); r.Err != ErrNoPipeline {
+		t.Error("wrong value", r)
+	}
+}
+
+func TestTcpClient_Reconnect(t *testing.T) {
+	// fake server: the first connection is closed with the first command
+	l, err := net.Listen("tcp", "localhost:3334")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for i := 1; ; i++ {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprintf(conn, "welcome %d\n", i)
+			go func(i int) {
+				tp := textproto.NewReader(bufio.NewReader(conn))
+				for n := 1; ; n++ {
+					if _, err := tp.ReadLine(); err != nil {
+						return
+					}
+					if i == 1 {
+						_ = conn.Close()
+						return
+					}
+					_, _ = conn.Write([]byte("ok\r\n"))
+				}
+			}(i)
+		}
+	}()
+
+	client, err := NewTcpClient("localhost", "3334")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var disconnects int
+	var welcome string
+	client.SetHooks(Hooks{
+		Reconnect:    true,
+		OnDisconnect: func(err error) { disconnects++ },
+		OnReconnect:  func(w string) { welcome = w },
+	})
+	if err := client.Stop("1"); err != nil || disconnects != 1 || welcome != "welcome 2" {
+		t.Error("wrong value", err, disconnects, welcome)
+	}
+	if err := client.Stop("1"); err != nil || disconnects != 1 {
+		t.Error("wrong value", err, disconnects)
+	}
+
+	// no server
+	if _, err := NewTcpClient("localhost", "3335"); err == nil {
+		t.Error("connection error expected")
+	}
+}
+
 // prettyString returns JSON as pretty string
 func prettyString(str []byte) (string, error) {
 	var prettyJSON bytes.Buffer
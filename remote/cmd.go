@@ -1,11 +1,14 @@
 package remote
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"github.com/SchnorcherSepp/TankWars/behavior"
 	"github.com/SchnorcherSepp/TankWars/core"
 	"github.com/SchnorcherSepp/TankWars/macro"
+	"github.com/SchnorcherSepp/TankWars/script"
 	"strconv"
+	"strings"
 )
 
 //---------------- GETTER --------------------------------------------------------------------------------------------//
@@ -18,19 +21,49 @@ func MyName(owner string) string {
 // GameStatus returns a json with all world data.
 func GameStatus(w *core.World) string {
 	if w == nil {
-		return "err: invalid world status"
+		return fail(CodeInternal, NameInvalidWorld, "invalid world status")
 	}
 
 	jw := NewJsonWorld(w)
 	return jw.Get()
 }
 
+// GameResult returns a json with the game state and the winner and reason of a finished game.
+func GameResult(w *core.World) string {
+	if w == nil {
+		return fail(CodeInternal, NameInvalidWorld, "invalid world status")
+	}
+
+	jr := NewJsonGameResult(w)
+	return jr.Get()
+}
+
+// Events returns a json list with all game events since the sequence number (see core.World.Events).
+// An empty sinceSeq returns all known events.
+func Events(w *core.World, sinceSeq string) string {
+	if w == nil {
+		return fail(CodeInternal, NameInvalidWorld, "invalid world status")
+	}
+
+	var seq uint64
+	if sinceSeq != "" {
+		s, err := strconv.ParseUint(sinceSeq, 10, 64)
+		if err != nil {
+			return failArg("sinceSeq", err.Error())
+		}
+		seq = s
+	}
+
+	es := NewJsonEvents(w.Events(seq))
+	return es.Get()
+}
+
 // TankStatus returns a json with all data of a requested tank.
 func TankStatus(w *core.World, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, "", tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -45,7 +78,7 @@ func CloseTargets(w *core.World, tankID string, filter ...string) string {
 	// get tank
 	t, err := id2Tank(w, "", tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -62,7 +95,7 @@ func PossibleTargets(w *core.World, tankID string, filter ...string) string {
 	// get tank
 	t, err := id2Tank(w, "", tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -78,23 +111,23 @@ func BuyTank(w *core.World, owner, armor, damage, weapon string) string {
 	// convert input
 	a, err := strconv.Atoi(armor)
 	if err != nil {
-		return "err: armor: " + err.Error()
+		return failArg("armor", err.Error())
 	}
 	d, err := strconv.Atoi(damage)
 	if err != nil {
-		return "err: damage: " + err.Error()
+		return failArg("damage", err.Error())
 	}
 
 	// build tank
 	t, err := core.NewTank(w, owner, a, d, weapon)
 	if err != nil {
-		return "err: " + err.Error()
+		return fail(CodeBadRequest, NameInvalidTank, err.Error())
 	}
 
 	// return
 	err = w.BuyTank(t)
 	if err != nil {
-		return "err: " + err.Error()
+		return failErr(err)
 	} else {
 		return fmt.Sprintf("ok %s", t.ID())
 	}
@@ -108,17 +141,17 @@ func Fire(w *core.World, owner, tankID, angle, distance string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// convert input
 	a, err := strconv.Atoi(angle)
 	if err != nil {
-		return "err: angle: " + err.Error()
+		return failArg("angle", err.Error())
 	}
 	d, err := strconv.Atoi(distance)
 	if err != nil {
-		return "err: distance: " + err.Error()
+		return failArg("distance", err.Error())
 	}
 
 	// return
@@ -126,7 +159,7 @@ func Fire(w *core.World, owner, tankID, angle, distance string) string {
 	if ok {
 		return "ok"
 	} else {
-		return "err: " + txt
+		return failStatus(txt)
 	}
 }
 
@@ -135,17 +168,17 @@ func FireAt(w *core.World, owner, tankID, x, y string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// convert input
 	xInt, err := strconv.Atoi(x)
 	if err != nil {
-		return "err: X: " + err.Error()
+		return failArg("X", err.Error())
 	}
 	yInt, err := strconv.Atoi(y)
 	if err != nil {
-		return "err: Y: " + err.Error()
+		return failArg("Y", err.Error())
 	}
 
 	// return
@@ -153,7 +186,7 @@ func FireAt(w *core.World, owner, tankID, x, y string) string {
 	if ok {
 		return "ok"
 	} else {
-		return "err: " + txt
+		return failStatus(txt)
 	}
 }
 
@@ -162,7 +195,7 @@ func Forward(w *core.World, owner, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -175,7 +208,7 @@ func Backward(w *core.World, owner, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -189,7 +222,7 @@ func Stop(w *core.World, owner, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -202,7 +235,7 @@ func Left(w *core.World, owner, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -210,7 +243,7 @@ func Left(w *core.World, owner, tankID string) string {
 	if ok {
 		return "ok"
 	} else {
-		return "err: " + txt
+		return failStatus(txt)
 	}
 }
 
@@ -219,7 +252,7 @@ func Right(w *core.World, owner, tankID string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// return
@@ -227,7 +260,7 @@ func Right(w *core.World, owner, tankID string) string {
 	if ok {
 		return "ok"
 	} else {
-		return "err: " + txt
+		return failStatus(txt)
 	}
 }
 
@@ -236,77 +269,341 @@ func SetMacroMoveTo(w *core.World, owner, tankID, x, y string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// convert input
 	xInt, err := strconv.Atoi(x)
 	if err != nil {
-		return "err: X: " + err.Error()
+		return failArg("X", err.Error())
 	}
 	yInt, err := strconv.Atoi(y)
 	if err != nil {
-		return "err: Y: " + err.Error()
+		return failArg("Y", err.Error())
 	}
 
 	// set macro
 	f := func(t *core.Tank) {
 		macro.MoveTo(t, core.NewPosition(xInt, yInt))
 	}
-	t.SetMacro(f)
+	t.SetNamedMacro(core.MacroMoveTo, f)
 
 	// return
 	return "ok"
 }
 
 // SetMacro sets a macro that is called with every update.
-// (see MacroAttackMove, MacroFireWall, MacroFireAndManeuver, MacroGuardMode and MacroReset)
-func SetMacro(w *core.World, owner, tankID, mco string) string {
+// (see MacroAttackMove, MacroFireWall, MacroFireAndManeuver, MacroGuardMode, MacroKite, MacroDodge and MacroReset)
+// MacroDodge wraps the active macro or the order queue.
+func SetMacro(w *core.World, sc *Scripts, owner, tankID, mco string) string {
 	// get tank
 	t, err := id2Tank(w, owner, tankID)
 	if err != nil {
-		return err.Error()
+		return failErr(err)
 	}
 
 	// convert input
 	switch mco {
-	case core.MacroAttackMove:
-		var filters = genFilters(t)
-		f := func(t *core.Tank) {
-			macro.AttackMove(t, filters...)
+	case "", core.MacroReset, "reset", "null", "remove", "disable":
+		t.SetMacro(nil)
+		return "ok: disable macro"
+	case core.MacroDodge:
+		if t.MacroName() != core.MacroDodge {
+			d := macro.NewDodge(t.Macro())
+			t.SetNamedMacro(core.MacroDodge, d.Update)
 		}
-		t.SetMacro(f)
 		return "ok"
+	}
 
-	case core.MacroFireAndManeuver:
-		f := func(t *core.Tank) {
-			macro.FireAndManeuver(t)
-		}
-		t.SetMacro(f)
-		return "ok"
+	// set macro
+	f := macroFunc(sc, t, mco)
+	if f == nil {
+		return fail(CodeNotFound, NameMacroNotFound, "macro not found")
+	}
+	t.SetNamedMacro(mco, f)
+	return "ok"
+}
 
-	case core.MacroFireWall:
-		f := func(t *core.Tank) {
-			macro.FireWall(t)
+// QueueOrder appends an order to the order queue of the tank (e.g. "MoveTo 300 400" or "GuardMode").
+// The orders are executed in sequence. An active macro is removed (except MacroDodge).
+func QueueOrder(w *core.World, sc *Scripts, owner, tankID string, args ...string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	o, err := newOrder(w, sc, t, args...)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// queue order
+	if t.MacroName() != core.MacroDodge {
+		t.SetMacro(nil) // Dodge resumes the queue
+	}
+	t.QueueOrder(o)
+
+	// return
+	return "ok"
+}
+
+// ClearOrders removes all queued orders of the tank.
+func ClearOrders(w *core.World, owner, tankID string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// clear
+	t.ClearOrders()
+
+	// return
+	return "ok"
+}
+
+// SetMacroRetreat sets a special macro that calls the combat macro as long as the tank is healthy.
+// Below the health threshold, the tank returns to its home base and stays there until it is fully repaired.
+// The combat macro is optional (default: MacroAttackMove).
+func SetMacroRetreat(w *core.World, sc *Scripts, owner, tankID, threshold, combat string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	th, err := strconv.Atoi(threshold)
+	if err != nil {
+		return failArg("threshold", err.Error())
+	}
+	if combat == "" {
+		combat = core.MacroAttackMove
+	}
+	cf := macroFunc(sc, t, combat)
+	if cf == nil {
+		return fail(CodeNotFound, NameMacroNotFound, "macro not found")
+	}
+
+	// set macro
+	f := func(t *core.Tank) {
+		macro.Retreat(t, th, cf)
+	}
+	t.SetNamedMacro(core.MacroRetreat, f)
+
+	// return
+	return "ok"
+}
+
+// Patrol sets a special macro that moves the tank along the waypoints (x1 y1 x2 y2 ...) in a loop.
+// If the tank encounters an enemy, it stops and opens fire.
+func Patrol(w *core.World, owner, tankID string, coords ...string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	waypoints := make([]core.Position, 0, len(coords)/2)
+	for i := 0; i+1 < len(coords); i += 2 {
+		xInt, err := strconv.Atoi(coords[i])
+		if err != nil {
+			return failArg("X", err.Error())
 		}
-		t.SetMacro(f)
-		return "ok"
+		yInt, err := strconv.Atoi(coords[i+1])
+		if err != nil {
+			return failArg("Y", err.Error())
+		}
+		waypoints = append(waypoints, core.NewPosition(xInt, yInt))
+	}
+	if len(waypoints) == 0 || len(coords)%2 != 0 {
+		return failArg("waypoints", "expected x1 y1 x2 y2 ...")
+	}
 
-	case core.MacroGuardMode:
-		var filters = genFilters(t)
-		f := func(t *core.Tank) {
-			macro.GuardMode(t, filters...)
+	// set macro
+	p := macro.NewPatrol(waypoints, macro.Filters(t)...)
+	t.SetNamedMacro(core.MacroPatrol, p.Update)
+
+	// return
+	return "ok"
+}
+
+// Follow sets a special macro that keeps the tank within the given distance of the leader.
+func Follow(w *core.World, owner, tankID, leaderID, distance string) string {
+	// get tanks
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+	leader, err := id2Tank(w, "", leaderID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	d, err := strconv.Atoi(distance)
+	if err != nil {
+		return failArg("distance", err.Error())
+	}
+
+	// set macro
+	f := func(t *core.Tank) {
+		macro.Follow(t, leader, d)
+	}
+	t.SetNamedMacro(core.MacroFollow, f)
+
+	// return
+	return "ok"
+}
+
+// Escort sets a special macro that follows the leader and engages anything attacking the leader.
+func Escort(w *core.World, owner, tankID, leaderID, distance string) string {
+	// get tanks
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+	leader, err := id2Tank(w, "", leaderID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	d, err := strconv.Atoi(distance)
+	if err != nil {
+		return failArg("distance", err.Error())
+	}
+
+	// set macro
+	e := macro.NewEscort(leader, d, macro.Filters(t)...)
+	t.SetNamedMacro(core.MacroEscort, e.Update)
+
+	// return
+	return "ok"
+}
+
+// SetBehavior sets a behavior tree loaded from JSON as macro (see behavior.Parse).
+// The tree is evaluated with every update.
+func SetBehavior(w *core.World, owner, tankID, tree string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	tr, err := behavior.Parse([]byte(tree))
+	if err != nil {
+		return fail(CodeBadRequest, NameInvalidBehavior, "behavior: "+err.Error())
+	}
+
+	// set macro
+	t.SetNamedMacro(core.MacroBehavior, tr.Update)
+
+	// return
+	return "ok"
+}
+
+// DefineMacro compiles a user-defined macro script (see package script).
+// The macro can then be used like a built-in macro by the same player (see SetMacro and QueueOrder).
+// An existing script with the same name is replaced; built-in macro names are reserved.
+// Only players can define macros (max. MaxScripts per player).
+func DefineMacro(sc *Scripts, owner, name, src string) string {
+	// check name
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return fail(CodeBadRequest, NameInvalidName, "invalid macro name")
+	}
+	if isBuiltinMacro(name) {
+		return fail(CodeBadRequest, NameInvalidName, "macro name is reserved")
+	}
+
+	// compile
+	p, err := script.Compile(name, src)
+	if err != nil {
+		return fail(CodeBadRequest, NameInvalidScript, "script: "+err.Error())
+	}
+
+	// register
+	if err := sc.define(owner, p); err != nil {
+		return failErr(err)
+	}
+
+	// return
+	return "ok"
+}
+
+// SetStrategy sets the target selection strategy used by the macros of a tank.
+// (see StrategyRotation, StrategyClosest, StrategyLowestHealth, StrategyHighestThreat, StrategyBuildingsFirst,
+// StrategyFocusFire and StrategyNoOverkill)
+func SetStrategy(w *core.World, owner, tankID, strategy string) string {
+	// get tank
+	t, err := id2Tank(w, owner, tankID)
+	if err != nil {
+		return failErr(err)
+	}
+
+	// convert input
+	if strategy == "" || strategy == core.MacroReset {
+		t.SetStrategy("")
+		return "ok: default strategy"
+	}
+	if !macro.IsStrategy(strategy) {
+		return fail(CodeNotFound, NameStrategyNotFound, "strategy not found")
+	}
+
+	// set strategy
+	t.SetStrategy(strategy)
+	return "ok"
+}
+
+// Batch runs several commands in the same iteration (see core.World.Lock) and returns a json list of all responses.
+// The commands are a json list of command lines or separated by ';'.
+// Without codes, the error codes are removed from the responses (see WithoutErrorCode).
+func Batch(w *core.World, sc *Scripts, owner, commands string, codes bool) string {
+	if w == nil {
+		return fail(CodeInternal, NameInvalidWorld, "invalid world status")
+	}
+
+	// parse commands
+	var list []string
+	commands = strings.TrimSpace(commands)
+	if strings.HasPrefix(commands, "[") {
+		if err := json.Unmarshal([]byte(commands), &list); err != nil {
+			return failArg("commands", err.Error())
 		}
-		t.SetMacro(f)
-		return "ok"
+	} else {
+		for _, cmd := range strings.Split(commands, ";") {
+			if cmd = strings.TrimSpace(cmd); cmd != "" {
+				list = append(list, cmd)
+			}
+		}
+	}
+	if len(list) == 0 {
+		return failArg("commands", "empty batch")
+	}
 
-	case "", core.MacroReset, "reset", "null", "remove", "disable":
-		t.SetMacro(nil)
-		return "ok: disable macro"
-	default:
-		t.SetMacro(nil)
-		return "err: macro not found"
+	// execute
+	w.Lock()
+	defer w.Unlock()
+
+	results := make([]string, len(list))
+	for i, cmd := range list {
+		if strings.HasPrefix(strings.TrimSpace(cmd)+" ", "Batch ") {
+			results[i] = failErr(ErrInvalidCommand) // no nested batches
+		} else {
+			results[i] = Execute(w, sc, owner, cmd)
+		}
+		if !codes {
+			results[i] = WithoutErrorCode(results[i])
+		}
 	}
+
+	// return
+	b, _ := json.Marshal(results)
+	return string(b)
 }
 
 //---------------- HELPER --------------------------------------------------------------------------------------------//
@@ -322,32 +619,127 @@ func id2Tank(w *core.World, owner, id string) (*core.Tank, error) {
 				if owner == "" || owner == t.Owner() {
 					return t, nil // no owner oo correct owner
 				} else {
-					return nil, errors.New("err: no access to other players units") // wrong owner
+					return nil, ErrNoAccess // wrong owner
 				}
 			}
 		}
 	}
 
 	// err: no tank
-	return nil, errors.New("err: tank not found")
+	return nil, ErrTankNotFound
 }
 
-// genFilters is a helper function and generate filter based on the owner.
-func genFilters(t *core.Tank) []string {
-	filters := make([]string, 0, 6)
+// macroFunc is a helper function and returns the macro function by name.
+// Returns nil if the macro is unknown.
+func macroFunc(sc *Scripts, t *core.Tank, mco string) func(t *core.Tank) {
+	var filters = macro.Filters(t)
 
-	if t != nil {
-		owner := t.Owner() // RedTank or BlueTank
+	switch mco {
+	case core.MacroAttackMove:
+		return func(t *core.Tank) {
+			macro.AttackMove(t, filters...)
+		}
+	case core.MacroFireAndManeuver:
+		return func(t *core.Tank) {
+			macro.FireAndManeuver(t)
+		}
+	case core.MacroFireWall:
+		return func(t *core.Tank) {
+			macro.FireWall(t)
+		}
+	case core.MacroGuardMode:
+		return func(t *core.Tank) {
+			macro.GuardMode(t, filters...)
+		}
+	case core.MacroKite:
+		return func(t *core.Tank) {
+			macro.Kite(t, filters...)
+		}
+	default:
+		// user-defined macro (see DefineMacro)
+		p := sc.lookup(t.Owner(), mco)
+		if p == nil {
+			return nil
+		}
+		return script.NewMachine(p).Update
+	}
+}
 
-		// own tanks and own base
-		filters = append(filters, owner)
-		filters = append(filters, owner+"_base")
+// isBuiltinMacro is a helper function and returns true if the name is used by a built-in macro or command.
+func isBuiltinMacro(name string) bool {
+	switch name {
+	case core.MacroAttackMove, core.MacroFireAndManeuver, core.MacroFireWall, core.MacroGuardMode, core.MacroKite,
+		core.MacroDodge, core.MacroReset, core.MacroMoveTo, core.MacroRetreat, core.MacroPatrol, core.MacroFollow,
+		core.MacroEscort, core.MacroBehavior, "reset", "null", "remove", "disable":
+		return true
+	default:
+		return false
+	}
+}
 
-		// all rocks
-		filters = append(filters, core.NeutralRock)
-		filters = append(filters, core.RedRock)
-		filters = append(filters, core.BlueRock)
+// newOrder is a helper function and creates an order from the arguments (name and parameters).
+// MoveTo, Follow and GuardMode (after the first target) can complete; all other macros run until the queue is cleared.
+func newOrder(w *core.World, sc *Scripts, t *core.Tank, args ...string) (core.Order, error) {
+	if len(args) == 0 || args[0] == "" {
+		return core.Order{}, Error{Code: CodeNotFound, Name: NameOrderNotFound, Msg: "order not found"}
 	}
+	name := strings.TrimSpace(strings.Join(args, " "))
+	filters := macro.Filters(t)
 
-	return filters
+	switch args[0] {
+	case core.MacroMoveTo:
+		if len(args) != 3 {
+			return core.Order{}, argError("MoveTo", "expected x y")
+		}
+		xInt, err := strconv.Atoi(args[1])
+		if err != nil {
+			return core.Order{}, argError("X", err.Error())
+		}
+		yInt, err := strconv.Atoi(args[2])
+		if err != nil {
+			return core.Order{}, argError("Y", err.Error())
+		}
+		to := core.NewPosition(xInt, yInt)
+		return core.Order{Name: name, Run: func(t *core.Tank) core.OrderState {
+			return macro.MoveTo(t, to)
+		}}, nil
+
+	case core.MacroFollow:
+		if len(args) != 3 {
+			return core.Order{}, argError("Follow", "expected leaderID distance")
+		}
+		leader, err := id2Tank(w, "", args[1])
+		if err != nil {
+			return core.Order{}, err
+		}
+		d, err := strconv.Atoi(args[2])
+		if err != nil {
+			return core.Order{}, argError("distance", err.Error())
+		}
+		return core.Order{Name: name, Run: func(t *core.Tank) core.OrderState {
+			return macro.Follow(t, leader, d)
+		}}, nil
+
+	case core.MacroGuardMode:
+		var engaged bool // GuardMode waits for the first target
+		return core.Order{Name: name, Run: func(t *core.Tank) core.OrderState {
+			state := macro.GuardMode(t, filters...)
+			if state == core.OrderRunning {
+				engaged = true
+			} else if state == core.OrderDone && !engaged {
+				return core.OrderRunning
+			}
+			return state
+		}}, nil
+
+	default:
+		f := macroFunc(sc, t, args[0])
+		if f == nil {
+			return core.Order{}, Error{Code: CodeNotFound, Name: NameOrderNotFound, Msg: "order not found"}
+		}
+		return core.Order{Name: name, Run: func(t *core.Tank) core.OrderState {
+			f(t)
+			return core.OrderRunning
+		}}, nil
+	}
 }
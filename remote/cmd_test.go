@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"fmt"
 	"github.com/SchnorcherSepp/TankWars/core"
 	"github.com/SchnorcherSepp/TankWars/gui/resources"
 	"strings"
@@ -55,7 +56,7 @@ func TestCloseTargets(t *testing.T) {
 	blue.SetPosition(core.NewPosition(200, 100), core.East)
 	w.AddTank(blue)
 
-	if ct := CloseTargets(w, "id"); ct != "err: tank not found" {
+	if ct := CloseTargets(w, "id"); ct != "err 404 tank_not_found tank not found" {
 		t.Error(ct)
 	}
 	if ct := CloseTargets(w, red.ID()); !strings.Contains(ct, `,"distance":100,"relativeAngle":90}]`) {
@@ -73,19 +74,19 @@ func TestSetMacroMoveTo(t *testing.T) {
 	w.AddTank(rock)
 
 	// test nil
-	if s := SetMacroMoveTo(nil, "", "", "", ""); s != "err: tank not found" {
+	if s := SetMacroMoveTo(nil, "", "", "", ""); s != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", s)
 	}
 	// wrong id
-	if s := SetMacroMoveTo(w, "", "wrong", "", ""); s != "err: tank not found" {
+	if s := SetMacroMoveTo(w, "", "wrong", "", ""); s != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", s)
 	}
 	// wrong X
-	if s := SetMacroMoveTo(w, "", nt.ID(), "w", ""); s != "err: X: strconv.Atoi: parsing \"w\": invalid syntax" {
+	if s := SetMacroMoveTo(w, "", nt.ID(), "w", ""); s != "err 400 invalid_argument X: strconv.Atoi: parsing \"w\": invalid syntax" {
 		t.Error("wrong value", s)
 	}
 	// wrong Y
-	if s := SetMacroMoveTo(w, "", nt.ID(), "700", "w"); s != "err: Y: strconv.Atoi: parsing \"w\": invalid syntax" {
+	if s := SetMacroMoveTo(w, "", nt.ID(), "700", "w"); s != "err 400 invalid_argument Y: strconv.Atoi: parsing \"w\": invalid syntax" {
 		t.Error("wrong value", s)
 	}
 
@@ -110,7 +111,7 @@ func TestPossibleTargets(t *testing.T) {
 	red.SetPosition(core.NewPosition(100, 100), core.East)
 	w.AddTank(red)
 
-	if pt := PossibleTargets(w, "id"); pt != "err: tank not found" {
+	if pt := PossibleTargets(w, "id"); pt != "err 404 tank_not_found tank not found" {
 		t.Error(pt)
 	}
 	if pt := PossibleTargets(w, red.ID()); pt != "[]" {
@@ -121,7 +122,7 @@ func TestPossibleTargets(t *testing.T) {
 func TestGameStatus(t *testing.T) {
 	w := core.NewWorld(100, 200)
 
-	if gs := GameStatus(nil); gs != "err: invalid world status" {
+	if gs := GameStatus(nil); gs != "err 500 invalid_world invalid world status" {
 		t.Error(gs)
 	}
 	if gs := GameStatus(w); len(gs) < 100 {
@@ -129,15 +130,47 @@ func TestGameStatus(t *testing.T) {
 	}
 }
 
+func TestGameResult(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	w.Start(0)
+
+	if resp := GameResult(nil); resp != "err 500 invalid_world invalid world status" {
+		t.Error("wrong value", resp)
+	}
+	if resp := GameResult(w); resp != `{"state":"running","winner":"","reason":""}` {
+		t.Error("wrong value", resp)
+	}
+
+	// game over
+	w.Finish(core.BlueTank, core.ReasonForfeit)
+	if resp := Execute(w, nil, core.RedTank, "GameResult"); resp != `{"state":"finished","winner":"blue","reason":"forfeit"}` {
+		t.Error("wrong value", resp)
+	}
+	if resp := Execute(w, nil, core.RedTank, "BuyTank 5 40 cannon"); resp != "err 409 game_over game is over" {
+		t.Error("wrong value", resp)
+	}
+	if resp := Batch(w, nil, core.RedTank, "MyName; Stop x", true); resp != `["red","err 409 game_over game is over"]` {
+		t.Error("wrong value", resp)
+	}
+	if resp := Execute(w, nil, core.RedTank, "Batch MyName; Stop x"); resp != `["red","err 409 game_over game is over"]` {
+		t.Error("wrong value", resp)
+	}
+	jw := JsonWorld{}
+	jw.Set(GameStatus(w))
+	if jw.State != core.StateFinished || jw.Winner != core.BlueTank || jw.Reason != core.ReasonForfeit {
+		t.Error("wrong value", jw.State, jw.Winner, jw.Reason)
+	}
+}
+
 func TestTankStatus(t *testing.T) {
 	w := core.NewWorld(100, 200)
 	red, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponRockets)
 	w.AddTank(red)
 
-	if ts := TankStatus(w, "id"); ts != "err: tank not found" {
+	if ts := TankStatus(w, "id"); ts != "err 404 tank_not_found tank not found" {
 		t.Error(ts)
 	}
-	if ts := TankStatus(nil, "id"); ts != "err: tank not found" {
+	if ts := TankStatus(nil, "id"); ts != "err 404 tank_not_found tank not found" {
 		t.Error(ts)
 	}
 	if ts := TankStatus(w, red.ID()); len(ts) < 100 {
@@ -159,16 +192,16 @@ func TestFire(t *testing.T) {
 	w.AddTank(blue)
 
 	// test Fire
-	if txt := Fire(w, "", "id", "0", "100"); txt != "err: tank not found" {
+	if txt := Fire(w, "", "id", "0", "100"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Fire(w, "", red.ID(), "0!", "100"); txt != "err: angle: strconv.Atoi: parsing \"0!\": invalid syntax" {
+	if txt := Fire(w, "", red.ID(), "0!", "100"); txt != "err 400 invalid_argument angle: strconv.Atoi: parsing \"0!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Fire(w, "", red.ID(), "0", "100!"); txt != "err: distance: strconv.Atoi: parsing \"100!\": invalid syntax" {
+	if txt := Fire(w, "", red.ID(), "0", "100!"); txt != "err 400 invalid_argument distance: strconv.Atoi: parsing \"100!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Fire(w, "", red.ID(), "0", "100"); txt != "err: Preparing" {
+	if txt := Fire(w, "", red.ID(), "0", "100"); txt != "err 409 preparing Preparing" {
 		t.Error("wrong value", txt)
 	}
 
@@ -185,19 +218,19 @@ func TestFire(t *testing.T) {
 	}
 
 	// test FireAt
-	if txt := FireAt(w, "", "id", "200", "100"); txt != "err: tank not found" {
+	if txt := FireAt(w, "", "id", "200", "100"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := FireAt(w, "", red.ID(), "200!", "100"); txt != "err: X: strconv.Atoi: parsing \"200!\": invalid syntax" {
+	if txt := FireAt(w, "", red.ID(), "200!", "100"); txt != "err 400 invalid_argument X: strconv.Atoi: parsing \"200!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
-	if txt := FireAt(w, "", red.ID(), "200", "100!"); txt != "err: Y: strconv.Atoi: parsing \"100!\": invalid syntax" {
+	if txt := FireAt(w, "", red.ID(), "200", "100!"); txt != "err 400 invalid_argument Y: strconv.Atoi: parsing \"100!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
 	if txt := FireAt(w, "", red.ID(), "200", "100"); txt != "ok" {
 		t.Error("wrong value", txt)
 	}
-	if txt := FireAt(w, "", red.ID(), "200", "100"); txt != "err: Reloading" {
+	if txt := FireAt(w, "", red.ID(), "200", "100"); txt != "err 409 reloading Reloading" {
 		t.Error("wrong value", txt)
 	}
 
@@ -220,47 +253,345 @@ func TestSetMacro(t *testing.T) {
 	w.AddTank(nt)
 
 	// set
-	if txt := SetMacro(w, "", nt.ID(), core.MacroGuardMode); txt != "ok" || nt.ActiveMacro() != true {
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroGuardMode); txt != "ok" || nt.ActiveMacro() != true {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+	if txt := TankStatus(w, nt.ID()); !strings.Contains(txt, `"macro":"GuardMode","macroState":{"phase":"guarding","targetID":"","waypoint":0}`) {
+		t.Error("wrong value", txt)
+	}
+	nt.SetMacro(nil)
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroFireWall); txt != "ok" || nt.ActiveMacro() != true {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
 	nt.SetMacro(nil)
-	if txt := SetMacro(w, "", nt.ID(), core.MacroFireWall); txt != "ok" || nt.ActiveMacro() != true {
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroFireAndManeuver); txt != "ok" || nt.ActiveMacro() != true {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
 	nt.SetMacro(nil)
-	if txt := SetMacro(w, "", nt.ID(), core.MacroFireAndManeuver); txt != "ok" || nt.ActiveMacro() != true {
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroAttackMove); txt != "ok" || nt.ActiveMacro() != true {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
 	nt.SetMacro(nil)
-	if txt := SetMacro(w, "", nt.ID(), core.MacroAttackMove); txt != "ok" || nt.ActiveMacro() != true {
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroKite); txt != "ok" || nt.ActiveMacro() != true {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroDodge); txt != "ok" || nt.MacroName() != core.MacroDodge {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
+	nt.SetMacro(nil)
 
 	// remove
-	if txt := SetMacro(w, "", nt.ID(), "nothing"); txt != "err: macro not found" || nt.ActiveMacro() != false {
+	if txt := SetMacro(w, nil, "", nt.ID(), "nothing"); txt != "err 404 macro_not_found macro not found" || nt.ActiveMacro() != false {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
-	if txt := SetMacro(w, "", nt.ID(), ""); txt != "ok: disable macro" || nt.ActiveMacro() != false {
+	if txt := SetMacro(w, nil, "", nt.ID(), ""); txt != "ok: disable macro" || nt.ActiveMacro() != false {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
-	if txt := SetMacro(w, "", nt.ID(), core.MacroReset); txt != "ok: disable macro" || nt.ActiveMacro() != false {
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroReset); txt != "ok: disable macro" || nt.ActiveMacro() != false {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
 
 	// wrong id
-	if txt := SetMacro(w, "", "id", "nil"); txt != "err: tank not found" || nt.ActiveMacro() != false {
+	if txt := SetMacro(w, nil, "", "id", "nil"); txt != "err 404 tank_not_found tank not found" || nt.ActiveMacro() != false {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+}
+
+func TestSetMacroRetreat(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+
+	// errors
+	if txt := SetMacroRetreat(w, nil, "", "id", "50", ""); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetMacroRetreat(w, nil, "", nt.ID(), "50!", ""); txt != "err 400 invalid_argument threshold: strconv.Atoi: parsing \"50!\": invalid syntax" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetMacroRetreat(w, nil, "", nt.ID(), "50", "nothing"); txt != "err 404 macro_not_found macro not found" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+
+	// set
+	if txt := SetMacroRetreat(w, nil, "", nt.ID(), "50", ""); txt != "ok" || !nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+	nt.SetMacro(nil)
+	if txt := SetMacroRetreat(w, nil, "", nt.ID(), "50", core.MacroGuardMode); txt != "ok" || !nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+}
+
+func TestPatrol(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+
+	// errors
+	if txt := Patrol(w, "", "id", "10", "20"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := Patrol(w, "", nt.ID()); txt != "err 400 invalid_argument waypoints: expected x1 y1 x2 y2 ..." || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := Patrol(w, "", nt.ID(), "10", "20", "30"); txt != "err 400 invalid_argument waypoints: expected x1 y1 x2 y2 ..." || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := Patrol(w, "", nt.ID(), "10", "2x"); txt != "err 400 invalid_argument Y: strconv.Atoi: parsing \"2x\": invalid syntax" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+
+	// set
+	if txt := Patrol(w, "", nt.ID(), "10", "20", "30", "40"); txt != "ok" || !nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+}
+
+func TestFollow(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+	leader, _ := core.NewTank(w, core.BlueTank, 5, 15, core.WeaponRockets)
+	w.AddTank(leader)
+
+	// errors
+	if txt := Follow(w, "", "id", leader.ID(), "50"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := Follow(w, "", nt.ID(), "id", "50"); txt != "err 404 tank_not_found tank not found" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := Follow(w, "", nt.ID(), leader.ID(), "x"); txt != "err 400 invalid_argument distance: strconv.Atoi: parsing \"x\": invalid syntax" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+
+	// set (the leader can be a foreign tank)
+	if txt := Follow(w, core.RedTank, nt.ID(), leader.ID(), "50"); txt != "ok" || !nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+}
+
+func TestEscort(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+	leader, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(leader)
+
+	// errors
+	if txt := Escort(w, "", "id", leader.ID(), "50"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := Escort(w, "", nt.ID(), "id", "50"); txt != "err 404 tank_not_found tank not found" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := Escort(w, "", nt.ID(), leader.ID(), "x"); txt != "err 400 invalid_argument distance: strconv.Atoi: parsing \"x\": invalid syntax" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+
+	// set
+	if txt := Escort(w, core.RedTank, nt.ID(), leader.ID(), "50"); txt != "ok" || !nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+}
+
+func TestQueueOrder(t *testing.T) {
+	w := core.NewWorld(1000, 1000)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	nt.SetPosition(core.NewPosition(500, 500), core.North)
+	w.AddTank(nt)
+
+	// errors
+	if txt := QueueOrder(w, nil, "", "id", "GuardMode"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID()); txt != "err 404 order_not_found order not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), "nothing"); txt != "err 404 order_not_found order not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), "MoveTo", "300"); txt != "err 400 invalid_argument MoveTo: expected x y" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), "Follow", "id", "50"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if len(nt.Orders()) != 0 {
+		t.Error("wrong value", nt.Orders())
+	}
+
+	// queue
+	nt.SetMacro(func(t *core.Tank) {})
+	if txt := QueueOrder(w, nil, "", nt.ID(), "MoveTo", "500", "300"); txt != "ok" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), core.MacroGuardMode); txt != "ok" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), core.MacroAttackMove); txt != "ok" {
+		t.Error("wrong value", txt)
+	}
+	if o := nt.Orders(); len(o) != 3 || o[0] != "MoveTo 500 300" || o[1] != core.MacroGuardMode {
+		t.Error("wrong value", o)
+	}
+	if txt := TankStatus(w, nt.ID()); !strings.Contains(txt, `"orders":["MoveTo 500 300","GuardMode","AttackMove"]`) || !strings.Contains(txt, `"macro":""`) {
+		t.Error("wrong value", txt)
+	}
+
+	// Dodge resumes the queue
+	if txt := SetMacro(w, nil, "", nt.ID(), core.MacroDodge); txt != "ok" {
+		t.Error("wrong value", txt)
+	}
+	if txt := QueueOrder(w, nil, "", nt.ID(), core.MacroAttackMove); txt != "ok" || nt.MacroName() != core.MacroDodge {
+		t.Error("wrong value", txt)
+	}
+
+	// execute: MoveTo is done, GuardMode waits for the first target
+	w.UpdateN(500)
+	if o := nt.Orders(); len(o) != 3 || o[0] != core.MacroGuardMode || nt.Pos().Y > 340 {
+		t.Error("wrong value", o, nt.Pos())
+	}
+
+	// GuardMode is done without targets, AttackMove never ends
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 15, core.WeaponRockets)
+	blue.SetPosition(core.NewPosition(nt.Pos().X, nt.Pos().Y-150), core.North)
+	w.AddTank(blue)
+	w.UpdateN(5)
+	blue.Remove()
+	w.UpdateN(5)
+	if o := nt.Orders(); len(o) != 2 || o[0] != core.MacroAttackMove {
+		t.Error("wrong value", o)
+	}
+
+	// clear
+	if txt := ClearOrders(w, "", "id"); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := ClearOrders(w, "", nt.ID()); txt != "ok" || len(nt.Orders()) != 0 {
+		t.Error("wrong value", txt)
+	}
+}
+
+func TestSetBehavior(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+
+	// errors
+	if txt := SetBehavior(w, "", "id", `{"type":"action","name":"Stop"}`); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetBehavior(w, "", nt.ID(), `{"type":"action","name":"nothing"}`); txt != "err 400 invalid_behavior behavior: unknown action 'nothing'" || nt.ActiveMacro() {
+		t.Error("wrong value", txt)
+	}
+
+	// set
+	if txt := SetBehavior(w, "", nt.ID(), `{"type":"selector","children":[{"type":"action","name":"GuardMode"}]}`); txt != "ok" || nt.MacroName() != core.MacroBehavior {
 		t.Error("wrong value", txt)
 	}
 	nt.Update()
 }
 
+func TestDefineMacro(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	sc := NewScripts()
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+
+	// errors
+	if txt := DefineMacro(sc, core.RedTank, core.MacroKite, `stop();`); txt != "err 400 invalid_name macro name is reserved" {
+		t.Error("wrong value", txt)
+	}
+	if txt := DefineMacro(sc, core.RedTank, "", `stop();`); txt != "err 400 invalid_name invalid macro name" {
+		t.Error("wrong value", txt)
+	}
+	if txt := DefineMacro(sc, core.RedTank, "Spin", `nothing();`); txt != "err 400 invalid_script script: pos 0: unknown function 'nothing'" {
+		t.Error("wrong value", txt)
+	}
+
+	// define and set
+	if txt := DefineMacro(sc, core.RedTank, "Spin", `left();`); txt != "ok" {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetMacro(w, sc, core.RedTank, nt.ID(), "Spin"); txt != "ok" || nt.MacroName() != "Spin" {
+		t.Error("wrong value", txt)
+	}
+	nt.Update()
+	if txt := QueueOrder(w, sc, core.RedTank, nt.ID(), "Spin"); txt != "ok" || len(nt.Orders()) != 1 {
+		t.Error("wrong value", txt, nt.Orders())
+	}
+
+	// other players can't use the script
+	bt, _ := core.NewTank(w, core.BlueTank, 5, 15, core.WeaponRockets)
+	w.AddTank(bt)
+	if txt := SetMacro(w, sc, core.BlueTank, bt.ID(), "Spin"); txt != "err 404 macro_not_found macro not found" {
+		t.Error("wrong value", txt)
+	}
+
+	// only players and max. MaxScripts per player
+	if txt := DefineMacro(sc, "observer", "Spin", `left();`); txt != "err 403 forbidden only players can define macros" {
+		t.Error("wrong value", txt)
+	}
+	for i := 1; i < MaxScripts; i++ {
+		if txt := DefineMacro(sc, core.RedTank, fmt.Sprintf("Spin%d", i), `left();`); txt != "ok" {
+			t.Error("wrong value", i, txt)
+		}
+	}
+	if txt := DefineMacro(sc, core.RedTank, "Spin", `right();`); txt != "ok" {
+		t.Error("wrong value", txt)
+	}
+	if txt := DefineMacro(sc, core.RedTank, "Full", `left();`); txt != "err 409 too_many_macros too many macros (max. 32)" {
+		t.Error("wrong value", txt)
+	}
+	if txt := DefineMacro(nil, core.RedTank, "Spin", `left();`); txt != "err 403 forbidden macros are disabled" {
+		t.Error("wrong value", txt)
+	}
+}
+
+func TestSetStrategy(t *testing.T) {
+	w := core.NewWorld(100, 200)
+	nt, _ := core.NewTank(w, core.RedTank, 5, 15, core.WeaponRockets)
+	w.AddTank(nt)
+
+	// set
+	if txt := SetStrategy(w, "", nt.ID(), core.StrategyLowestHealth); txt != "ok" || nt.Strategy() != core.StrategyLowestHealth {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetStrategy(w, "", nt.ID(), "nothing"); txt != "err 404 strategy_not_found strategy not found" || nt.Strategy() != core.StrategyLowestHealth {
+		t.Error("wrong value", txt)
+	}
+
+	// reset
+	if txt := SetStrategy(w, "", nt.ID(), core.MacroReset); txt != "ok: default strategy" || nt.Strategy() != "" {
+		t.Error("wrong value", txt)
+	}
+
+	// wrong id or owner
+	if txt := SetStrategy(w, "", "id", core.StrategyClosest); txt != "err 404 tank_not_found tank not found" {
+		t.Error("wrong value", txt)
+	}
+	if txt := SetStrategy(w, core.BlueTank, nt.ID(), core.StrategyClosest); txt != "err 403 no_access no access to other players units" {
+		t.Error("wrong value", txt)
+	}
+}
+
 func TestMyName(t *testing.T) {
 	if MyName(core.RedTank) != "red" {
 		t.Error("wrong value")
@@ -274,13 +605,13 @@ func TestBuyTank(t *testing.T) {
 	w.SetCash(100, 0)
 
 	// invalid input
-	if txt := BuyTank(w, core.RedTank, "10!", "20", core.WeaponCannon); txt != "err: armor: strconv.Atoi: parsing \"10!\": invalid syntax" {
+	if txt := BuyTank(w, core.RedTank, "10!", "20", core.WeaponCannon); txt != "err 400 invalid_argument armor: strconv.Atoi: parsing \"10!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
-	if txt := BuyTank(w, core.RedTank, "10", "20!", core.WeaponCannon); txt != "err: damage: strconv.Atoi: parsing \"20!\": invalid syntax" {
+	if txt := BuyTank(w, core.RedTank, "10", "20!", core.WeaponCannon); txt != "err 400 invalid_argument damage: strconv.Atoi: parsing \"20!\": invalid syntax" {
 		t.Error("wrong value", txt)
 	}
-	if txt := BuyTank(w, core.RedTank, "10", "20", "no"); txt != "err: unknown weapon: no" {
+	if txt := BuyTank(w, core.RedTank, "10", "20", "no"); txt != "err 400 invalid_tank unknown weapon: no" {
 		t.Error("wrong value", "'", txt, "'")
 	}
 
@@ -288,14 +619,14 @@ func TestBuyTank(t *testing.T) {
 	if txt := BuyTank(w, core.RedTank, "10", "20", core.WeaponCannon); txt[:2] != "ok" {
 		t.Error("wrong value", txt)
 	}
-	if txt := BuyTank(w, core.RedTank, "10", "20", core.WeaponCannon); txt != "err: not enough tank budget or unknown owner" {
+	if txt := BuyTank(w, core.RedTank, "10", "20", core.WeaponCannon); txt != "err 409 no_budget not enough tank budget or unknown owner" {
 		t.Error("wrong value", txt)
 	}
 
 	// no base
 	w.SetCash(100, 0)
 	hb.Remove()
-	if txt := BuyTank(w, core.RedTank, "10", "20", core.WeaponCannon); txt != "err: home base not found" {
+	if txt := BuyTank(w, core.RedTank, "10", "20", core.WeaponCannon); txt != "err 404 base_not_found home base not found" {
 		t.Error("wrong value", txt)
 	}
 }
@@ -307,19 +638,19 @@ func TestMovement(t *testing.T) {
 	w.AddTank(nt)
 
 	// CHECK NIL
-	if txt := Left(w, "", "nil"); txt != "err: tank not found" {
+	if txt := Left(w, "", "nil"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Right(w, "", "nil"); txt != "err: tank not found" {
+	if txt := Right(w, "", "nil"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Forward(w, "", "nil"); txt != "err: tank not found" {
+	if txt := Forward(w, "", "nil"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Backward(w, "", "nil"); txt != "err: tank not found" {
+	if txt := Backward(w, "", "nil"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
-	if txt := Stop(w, "", "nil"); txt != "err: tank not found" {
+	if txt := Stop(w, "", "nil"); txt != "err 404 tank_not_found tank not found" {
 		t.Error("wrong value", txt)
 	}
 
@@ -333,14 +664,14 @@ func TestMovement(t *testing.T) {
 	if txt := Left(w, "", nt.ID()); nt.Angle() != core.West || txt != "ok" {
 		t.Error("wrong value", nt.Angle(), txt)
 	}
-	if txt := Left(w, "", nt.ID()); txt != "err: Preparing" { // timer
+	if txt := Left(w, "", nt.ID()); txt != "err 409 preparing Preparing" { // timer
 		t.Error("wrong value", txt)
 	}
 	w.UpdateN(core.TankRotationDelay)
 	if txt := Right(w, "", nt.ID()); nt.Angle() != core.Northwest || txt != "ok" {
 		t.Error("wrong value", nt.Angle(), txt)
 	}
-	if txt := Right(w, "", nt.ID()); txt != "err: Preparing" { // timer
+	if txt := Right(w, "", nt.ID()); txt != "err 409 preparing Preparing" { // timer
 		t.Error("wrong value", txt)
 	}
 
@@ -374,3 +705,76 @@ func TestMovement(t *testing.T) {
 		t.Error("wrong value", nt.Moving(), nt.Pos().X, nt.Pos().Y)
 	}
 }
+
+func TestEvents(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	red, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(red)
+	w.UpdateN(100) // weapon ready
+	red.Fire(core.East, 0)
+
+	if resp := Events(nil, ""); resp != "err 500 invalid_world invalid world status" {
+		t.Error("wrong value", resp)
+	}
+	if resp := Events(w, "x"); !strings.HasPrefix(resp, "err 400 invalid_argument sinceSeq: ") {
+		t.Error("wrong value", resp)
+	}
+
+	es := JsonEvents{}
+	es.Set(Events(w, ""))
+	if len(es) != 1 || es[0].Type != core.EventFired || es[0].TankID != red.ID() {
+		t.Error("wrong value", es)
+	}
+	if resp := Events(w, "1"); resp != "[]" {
+		t.Error("wrong value", resp)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	red, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 40, core.WeaponCannon)
+	blue.SetPosition(core.NewPosition(500, 100), core.East)
+	w.AddTank(blue)
+
+	// errors
+	if resp := Batch(nil, nil, core.RedTank, "MyName", false); resp != "err 500 invalid_world invalid world status" {
+		t.Error("wrong value", resp)
+	}
+	if resp := Batch(w, nil, core.RedTank, " ; ", false); resp != "err 400 invalid_argument commands: empty batch" {
+		t.Error("wrong value", resp)
+	}
+	if resp := Batch(w, nil, core.RedTank, "[\"MyName\"", false); !strings.HasPrefix(resp, "err 400 invalid_argument commands: ") {
+		t.Error("wrong value", resp)
+	}
+
+	// json
+	resp := Batch(w, nil, core.RedTank, "[\"Forward "+red.ID()+"\", \"Forward "+blue.ID()+"\", \"Batch MyName\", \"MyName\"]", false)
+	if want := "[\"ok\",\"err: no access to other players units\",\"err: invalid command\",\"red\"]"; resp != want {
+		t.Error("wrong value", resp)
+	}
+	if red.Command() != 1 || blue.Command() != 0 {
+		t.Error("wrong value", red.Command(), blue.Command())
+	}
+
+	// separated
+	resp = Batch(w, nil, core.RedTank, "Stop "+red.ID()+"; TankStatus x;", true)
+	if want := "[\"ok\",\"err 404 tank_not_found tank not found\"]"; resp != want {
+		t.Error("wrong value", resp)
+	}
+	if red.Command() != 0 {
+		t.Error("wrong value", red.Command())
+	}
+
+	// Execute
+	if resp := Execute(w, nil, core.RedTank, "Batch MyName;MyName"); resp != "[\"red\",\"red\"]" {
+		t.Error("wrong value", resp)
+	}
+}
@@ -0,0 +1,130 @@
+package remote
+
+import (
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/maps"
+	"strconv"
+	"strings"
+)
+
+// error codes of the protocol (see command ErrorCodes)
+const (
+	CodeBadRequest   = 400 // invalid command or arguments
+	CodeUnauthorized = 401 // not joined, invalid token or session (see Server.SetTokens)
+	CodeForbidden    = 403 // no access to other players units or admin commands
+	CodeNotFound     = 404 // tank, base, macro, order, strategy or map not found
+	CodeConflict     = 409 // not possible in the current state (e.g. reloading or no budget)
+	CodeInternal     = 500 // invalid world status or unexpected errors
+)
+
+// error names of the protocol (see command ErrorCodes)
+const (
+	NameInvalidCommand   = "invalid_command"
+	NameInvalidArgument  = "invalid_argument"
+	NameInvalidTank      = "invalid_tank"
+	NameInvalidName      = "invalid_name"
+	NameInvalidScript    = "invalid_script"
+	NameInvalidBehavior  = "invalid_behavior"
+	NameNoAccess         = "no_access"
+	NameTankNotFound     = "tank_not_found"
+	NameBaseNotFound     = "base_not_found"
+	NameMacroNotFound    = "macro_not_found"
+	NameOrderNotFound    = "order_not_found"
+	NameStrategyNotFound = "strategy_not_found"
+	NameMoving           = "moving"
+	NamePreparing        = "preparing"
+	NameReloading        = "reloading"
+	NameNoWeapon         = "no_weapon"
+	NameNoBudget         = "no_budget"
+	NameNoSpace          = "no_space"
+	NameInvalidWorld     = "invalid_world"
+	NameNotJoined        = "not_joined"
+	NameInvalidToken     = "invalid_token"
+	NameSlotTaken        = "slot_taken"
+	NameAlreadyJoined    = "already_joined"
+	NameInvalidSession   = "invalid_session"
+	NameForfeited        = "forfeited"
+	NameForbidden        = "forbidden"
+	NameMapNotFound      = "map_not_found"
+	NameGameOver         = "game_over"
+	NameTooManyMacros    = "too_many_macros"
+	NameInternal         = "internal"
+)
+
+// errorCodes are the codes and names of the sentinel errors (see failErr).
+var errorCodes = map[error]Error{
+	ErrInvalidCommand:  {Code: CodeBadRequest, Name: NameInvalidCommand},
+	ErrNoAccess:        {Code: CodeForbidden, Name: NameNoAccess},
+	ErrTankNotFound:    {Code: CodeNotFound, Name: NameTankNotFound},
+	core.ErrNoBudget:   {Code: CodeConflict, Name: NameNoBudget},
+	core.ErrNoHomeBase: {Code: CodeNotFound, Name: NameBaseNotFound},
+	core.ErrNoSpace:    {Code: CodeConflict, Name: NameNoSpace},
+	maps.ErrUnknownMap: {Code: CodeNotFound, Name: NameMapNotFound},
+}
+
+// statusNames are the names of the tank status (see failStatus).
+var statusNames = map[string]string{
+	core.StatusMoving:    NameMoving,
+	core.StatusPreparing: NamePreparing,
+	core.StatusReloading: NameReloading,
+	core.StatusNoWeapon:  NameNoWeapon,
+}
+
+// fail returns the error response "err {code} {name} {text}" of a command handler.
+// The server removes the code and name for connections without error codes (see WithoutErrorCode).
+func fail(code int, name, msg string) string {
+	return fmt.Sprintf("err %d %s %s", code, name, msg)
+}
+
+// failArg returns the error response of an invalid argument ("{arg}: {text}").
+func failArg(arg, msg string) string {
+	return fail(CodeBadRequest, NameInvalidArgument, arg+": "+msg)
+}
+
+// argError returns the error of an invalid argument (see failArg).
+func argError(arg, msg string) error {
+	return Error{Code: CodeBadRequest, Name: NameInvalidArgument, Msg: arg + ": " + msg}
+}
+
+// failStatus returns the error response of a tank that can't fire or rotate (see core.StatusReloading).
+func failStatus(status string) string {
+	if name, ok := statusNames[status]; ok {
+		return fail(CodeConflict, name, status)
+	}
+	return fail(CodeInternal, NameInternal, status)
+}
+
+// failErr returns the error response of an Error or a sentinel error (see errorCodes).
+// All other errors are unexpected and returned as internal errors.
+func failErr(err error) string {
+	if e, ok := err.(Error); ok {
+		return fail(e.Code, e.Name, e.Msg)
+	}
+	if e, ok := errorCodes[err]; ok {
+		return fail(e.Code, e.Name, err.Error())
+	}
+	return fail(CodeInternal, NameInternal, err.Error())
+}
+
+// WithoutErrorCode converts an error response "err {code} {name} {text}" to "err: {text}".
+// All other responses are returned unchanged.
+func WithoutErrorCode(resp string) string {
+	if _, _, msg, ok := splitError(resp); ok {
+		return "err: " + msg
+	}
+	return resp
+}
+
+// splitError splits an error response "err {code} {name} {text}" (see fail).
+func splitError(resp string) (code int, name, msg string, ok bool) {
+	f := strings.SplitN(resp, " ", 4)
+	if len(f) != 4 || f[0] != "err" {
+		return 0, "", "", false
+	}
+	code, err := strconv.Atoi(f[1])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return code, f[2], f[3], true
+}
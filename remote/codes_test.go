@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"errors"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"testing"
+)
+
+func TestWithoutErrorCode(t *testing.T) {
+	tests := []struct {
+		resp string
+		want string
+	}{
+		{"ok", "ok"},
+		{"ok 1234", "ok 1234"},
+		{"{\"id\":\"1234\"}", "{\"id\":\"1234\"}"},
+		{"err 404 tank_not_found tank not found", "err: tank not found"},
+		{"err 400 invalid_argument armor: strconv.Atoi: parsing \"x\": invalid syntax", "err: armor: strconv.Atoi: parsing \"x\": invalid syntax"},
+		{"err: tank not found", "err: tank not found"},
+	}
+	for _, tt := range tests {
+		if got := WithoutErrorCode(tt.resp); got != tt.want {
+			t.Error("wrong value", got)
+		}
+	}
+}
+
+func Test_fail(t *testing.T) {
+	tests := []struct {
+		resp string
+		want string
+	}{
+		{failErr(ErrTankNotFound), "err 404 tank_not_found tank not found"},
+		{failErr(core.ErrNoBudget), "err 409 no_budget not enough tank budget or unknown owner"},
+		{failErr(argError("X", "expected x y")), "err 400 invalid_argument X: expected x y"},
+		{failErr(errors.New("unexpected")), "err 500 internal unexpected"},
+		{failStatus(core.StatusReloading), "err 409 reloading Reloading"},
+		{failArg("armor", "expected a number"), "err 400 invalid_argument armor: expected a number"},
+	}
+	for _, tt := range tests {
+		if tt.resp != tt.want {
+			t.Error("wrong value", tt.resp)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	// no error
+	if err := ParseError("ok"); err != nil {
+		t.Error("wrong value", err)
+	}
+
+	// both formats
+	for _, resp := range []string{"err: tank not found", "err 404 tank_not_found tank not found"} {
+		if err := ParseError(resp); err != ErrTankNotFound {
+			t.Error("wrong value", err)
+		}
+	}
+	for _, resp := range []string{"err: Moving", "err 409 moving Moving"} {
+		if err := ParseError(resp); err != (ErrNotReady{Status: core.StatusMoving}) {
+			t.Error("wrong value", err)
+		}
+	}
+	if err := ParseError("err 404 macro_not_found macro not found"); err != (Error{Code: CodeNotFound, Name: NameMacroNotFound, Msg: "macro not found"}) {
+		t.Error("wrong value", err)
+	}
+	if err := ParseError("err: macro not found"); err != (Error{Msg: "macro not found"}) {
+		t.Error("wrong value", err) // no code without error codes
+	}
+
+	// client errors
+	if err := ParseError("err: " + ErrClosed.Error()); err != ErrClosed {
+		t.Error("wrong value", err)
+	}
+	if err := ParseError("err: TcpClient read: EOF"); err == nil || err.Error() != "TcpClient read: EOF" {
+		t.Error("wrong value", err)
+	}
+}
@@ -0,0 +1,255 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// historySize is the number of served world states that are kept for GameStatusSince.
+const historySize = 64
+
+//---------------- Delta ---------------------------------------------------------------------------------------------//
+
+// JsonWorldDelta is the response of GameStatusSince.
+// World contains all values of the world, but only the created or changed tanks and projectiles.
+// If Full is true, World is complete and replaces the old state (resync).
+// World.Epoch is increased with every new map, because the iterations start again (see history.reset).
+type JsonWorldDelta struct {
+	Full               bool      `json:"full"`
+	Since              uint64    `json:"since"`
+	World              JsonWorld `json:"world"`
+	Removed            []string  `json:"removed"`            // ids of the removed tanks
+	RemovedProjectiles []string  `json:"removedProjectiles"` // ids of the removed projectiles
+}
+
+// Get returns a json representation of this object
+func (d *JsonWorldDelta) Get() string {
+	b, err := json.Marshal(d)
+	if err != nil || d == nil {
+		fmt.Printf("err: JsonWorldDelta: %v\n", err)
+	}
+	return string(b)
+}
+
+// Set parse a json string and update the inner variables of this object
+func (d *JsonWorldDelta) Set(j string) {
+	if err := json.Unmarshal([]byte(j), &d); err != nil {
+		fmt.Printf("err: JsonWorldDelta: %v\n", err)
+	}
+}
+
+// Merge applies a delta to the world.
+// The world must be the state of the iteration d.Since in the same epoch (see GameStatusSince).
+func (w *JsonWorld) Merge(d *JsonWorldDelta) {
+	if d.Full {
+		*w = d.World
+		return // EXIT
+	}
+
+	// tanks
+	removed := ids(d.Removed)
+	changed := make(map[string]JsonTank, len(d.World.Tanks))
+	for _, t := range d.World.Tanks {
+		changed[t.ID] = t
+	}
+	tanks := make([]JsonTank, 0, len(w.Tanks)+len(d.World.Tanks))
+	for _, t := range w.Tanks {
+		if removed[t.ID] {
+			continue // removed
+		}
+		if ct, ok := changed[t.ID]; ok {
+			t = ct // changed
+			delete(changed, t.ID)
+		}
+		tanks = append(tanks, t)
+	}
+	for _, t := range d.World.Tanks {
+		if _, ok := changed[t.ID]; ok {
+			tanks = append(tanks, t) // created
+		}
+	}
+
+	// projectiles
+	removed = ids(d.RemovedProjectiles)
+	changedP := make(map[string]JsonProjectile, len(d.World.Projectiles))
+	for _, p := range d.World.Projectiles {
+		changedP[p.ID] = p
+	}
+	projectiles := make([]JsonProjectile, 0, len(w.Projectiles)+len(d.World.Projectiles))
+	for _, p := range w.Projectiles {
+		if removed[p.ID] {
+			continue // removed
+		}
+		if cp, ok := changedP[p.ID]; ok {
+			p = cp // changed
+			delete(changedP, p.ID)
+		}
+		projectiles = append(projectiles, p)
+	}
+	for _, p := range d.World.Projectiles {
+		if _, ok := changedP[p.ID]; ok {
+			projectiles = append(projectiles, p) // created
+		}
+	}
+
+	// world
+	*w = d.World
+	w.Tanks = tanks
+	w.Projectiles = projectiles
+}
+
+// ids returns a set of the ids.
+func ids(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, id := range list {
+		set[id] = true
+	}
+	return set
+}
+
+//---------------- History -------------------------------------------------------------------------------------------//
+
+// history is a ring buffer of the served world states (see GameStatusSince).
+type history struct {
+	mux     sync.Mutex
+	entries [historySize]*snapshot
+	next    int
+	epoch   uint64 // increased by reset
+}
+
+// snapshot is a served world state.
+type snapshot struct {
+	iteration   uint64
+	tanks       []JsonTank
+	projectiles []JsonProjectile
+	invalid     bool // the state has changed within the iteration
+}
+
+// since returns the delta of the world since the iteration of the epoch (see JsonWorldDelta).
+// Unknown iterations, an other epoch or empty strings return the full world.
+func (h *history) since(w *core.World, iteration, epoch string) string {
+	if w == nil {
+		return fail(CodeInternal, NameInvalidWorld, "invalid world status")
+	}
+	var since, known uint64
+	if iteration != "" {
+		i, err := strconv.ParseUint(iteration, 10, 64)
+		if err != nil {
+			return failArg("iteration", err.Error())
+		}
+		since = i
+	}
+	if epoch != "" {
+		e, err := strconv.ParseUint(epoch, 10, 64)
+		if err != nil {
+			return failArg("epoch", err.Error())
+		}
+		known = e
+	}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	// record (can invalidate the old snapshot)
+	jw := NewJsonWorld(w)
+	jw.Epoch = h.epoch
+	old := h.find(since)
+	h.record(jw)
+
+	// delta (the iterations of an other epoch belong to an other map)
+	d := JsonWorldDelta{Full: true, World: jw, Removed: []string{}, RemovedProjectiles: []string{}}
+	if iteration != "" && epoch != "" && known == h.epoch && old != nil && !old.invalid {
+		d.Full = false
+		d.Since = since
+		d.World.Tanks, d.Removed = diffTanks(old.tanks, jw.Tanks)
+		d.World.Projectiles, d.RemovedProjectiles = diffProjectiles(old.projectiles, jw.Projectiles)
+	}
+	return d.Get()
+}
+
+// reset removes all snapshots and starts a new epoch, because the iterations start again with a new map
+// (see Server.LoadMap).
+func (h *history) reset() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.entries = [historySize]*snapshot{}
+	h.next = 0
+	h.epoch++
+}
+
+// find returns the valid snapshot of the iteration or nil.
+func (h *history) find(iteration uint64) *snapshot {
+	for _, s := range h.entries {
+		if s != nil && s.iteration == iteration && !s.invalid {
+			return s
+		}
+	}
+	return nil
+}
+
+// record stores the world state.
+// A different state in the same iteration invalidates the snapshot (clients get the full world).
+func (h *history) record(jw JsonWorld) {
+	last := h.entries[(h.next+historySize-1)%historySize]
+	if last != nil && last.iteration == jw.Iteration {
+		if !reflect.DeepEqual(last.tanks, jw.Tanks) || !reflect.DeepEqual(last.projectiles, jw.Projectiles) {
+			last.invalid = true
+		}
+		return // EXIT
+	}
+	h.entries[h.next] = &snapshot{iteration: jw.Iteration, tanks: jw.Tanks, projectiles: jw.Projectiles}
+	h.next = (h.next + 1) % historySize
+}
+
+// diffTanks returns the created or changed tanks and the ids of the removed tanks.
+func diffTanks(old, tanks []JsonTank) (changed []JsonTank, removed []string) {
+	index := make(map[string]int, len(old))
+	for i, t := range old {
+		index[t.ID] = i
+	}
+
+	changed = make([]JsonTank, 0)
+	for _, t := range tanks {
+		if i, ok := index[t.ID]; !ok || !reflect.DeepEqual(old[i], t) {
+			changed = append(changed, t)
+		}
+		delete(index, t.ID)
+	}
+
+	removed = make([]string, 0, len(index))
+	for _, t := range old {
+		if _, ok := index[t.ID]; ok {
+			removed = append(removed, t.ID)
+		}
+	}
+	return changed, removed
+}
+
+// diffProjectiles returns the created or changed projectiles and the ids of the removed projectiles (see diffTanks).
+func diffProjectiles(old, projectiles []JsonProjectile) (changed []JsonProjectile, removed []string) {
+	index := make(map[string]int, len(old))
+	for i, p := range old {
+		index[p.ID] = i
+	}
+
+	changed = make([]JsonProjectile, 0)
+	for _, p := range projectiles {
+		if i, ok := index[p.ID]; !ok || old[i] != p {
+			changed = append(changed, p)
+		}
+		delete(index, p.ID)
+	}
+
+	removed = make([]string, 0, len(index))
+	for _, p := range old {
+		if _, ok := index[p.ID]; ok {
+			removed = append(removed, p.ID)
+		}
+	}
+	return changed, removed
+}
@@ -0,0 +1,162 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestHistory_since(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	red, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 40, core.WeaponCannon)
+	blue.SetPosition(core.NewPosition(500, 100), core.East)
+	w.AddTank(blue)
+	rock, _ := core.NewTank(w, core.NeutralRock, 5, 40, core.WeaponCannon)
+	rock.SetPosition(core.NewPosition(900, 100), core.East)
+	w.AddTank(rock)
+	h := new(history)
+
+	// errors
+	if resp := h.since(w, "x", "0"); resp != "err 400 invalid_argument iteration: strconv.ParseUint: parsing \"x\": invalid syntax" {
+		t.Error("wrong value", resp)
+	}
+
+	// full
+	d := new(JsonWorldDelta)
+	d.Set(h.since(w, "", "0"))
+	if !d.Full || len(d.World.Tanks) != 3 {
+		t.Error("wrong value", d)
+	}
+	client := new(JsonWorld)
+	client.Merge(d)
+
+	// delta: red moves, blue is destroyed
+	red.Forward()
+	w.Update()
+	w.Clear(core.BlueTank)
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, "0", "0"))
+	if d.Full || d.Since != 0 || d.World.Iteration != 1 {
+		t.Error("wrong value", d)
+	}
+	if len(d.World.Tanks) != 1 || d.World.Tanks[0].ID != red.ID() {
+		t.Error("wrong value", d.World.Tanks)
+	}
+	if len(d.Removed) != 1 || d.Removed[0] != blue.ID() {
+		t.Error("wrong value", d.Removed)
+	}
+	client.Merge(d)
+	if full := NewJsonWorld(w); !reflect.DeepEqual(*client, full) {
+		t.Error("wrong merge", *client, full)
+	}
+
+	// projectile: created
+	red.Stop()
+	for i := 0; i < 1000; i++ {
+		if ok, _ := red.Fire(core.East, 200); ok {
+			break
+		}
+		w.Update()
+	}
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, "1", "0"))
+	if d.Full || len(d.World.Projectiles) != 1 || len(d.RemovedProjectiles) != 0 {
+		t.Error("wrong value", d)
+	}
+	client.Merge(d)
+	if full := NewJsonWorld(w); !reflect.DeepEqual(*client, full) {
+		t.Error("wrong merge", *client, full)
+	}
+
+	// projectile: removed
+	id := client.Projectiles[0].ID
+	since := strconv.FormatUint(w.Iteration(), 10)
+	for i := 0; i < 1000 && len(w.Projectiles()) > 0; i++ {
+		w.Update()
+	}
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, since, "0"))
+	if d.Full || len(d.World.Projectiles) != 0 || len(d.RemovedProjectiles) != 1 || d.RemovedProjectiles[0] != id {
+		t.Error("wrong value", d)
+	}
+	client.Merge(d)
+	if full := NewJsonWorld(w); !reflect.DeepEqual(*client, full) {
+		t.Error("wrong merge", *client, full)
+	}
+
+	// unknown iteration
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, "99", "0"))
+	if !d.Full || len(d.World.Tanks) != 2 {
+		t.Error("wrong value", d)
+	}
+
+	// changed within the iteration
+	w.Clear(core.NeutralRock)
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, strconv.FormatUint(w.Iteration(), 10), "0"))
+	if !d.Full || len(d.World.Tanks) != 1 {
+		t.Error("wrong value", d)
+	}
+
+	// errors
+	if resp := h.since(w, "0", "x"); resp != "err 400 invalid_argument epoch: strconv.ParseUint: parsing \"x\": invalid syntax" {
+		t.Error("wrong value", resp)
+	}
+
+	// new map: the same iteration of an other epoch
+	i := strconv.FormatUint(w.Iteration(), 10)
+	h.reset()
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, "", ""))
+	if !d.Full || d.World.Epoch != 1 {
+		t.Error("wrong value", d)
+	}
+	for _, epoch := range []string{"0", ""} {
+		d = new(JsonWorldDelta)
+		d.Set(h.since(w, i, epoch))
+		if !d.Full || d.World.Epoch != 1 {
+			t.Error("wrong value", epoch, d)
+		}
+	}
+	d = new(JsonWorldDelta)
+	d.Set(h.since(w, i, "1"))
+	if d.Full || d.World.Epoch != 1 {
+		t.Error("wrong value", d)
+	}
+}
+
+func TestJsonWorld_Merge(t *testing.T) {
+	w := &JsonWorld{
+		Iteration: 5,
+		Tanks:     []JsonTank{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+	}
+	w.Merge(&JsonWorldDelta{
+		Since:   5,
+		World:   JsonWorld{Iteration: 6, Tanks: []JsonTank{{ID: "4"}, {ID: "2", Health: 50}}},
+		Removed: []string{"1"},
+	})
+	want := []JsonTank{{ID: "2", Health: 50}, {ID: "3"}, {ID: "4"}}
+	if w.Iteration != 6 || !reflect.DeepEqual(w.Tanks, want) {
+		t.Error("wrong value", w)
+	}
+
+	// projectiles
+	w.Projectiles = []JsonProjectile{{ID: "5"}, {ID: "6"}}
+	w.Merge(&JsonWorldDelta{
+		Since:              6,
+		World:              JsonWorld{Iteration: 7, Projectiles: []JsonProjectile{{ID: "6", Distance: 10}, {ID: "7"}}},
+		RemovedProjectiles: []string{"5"},
+	})
+	wantP := []JsonProjectile{{ID: "6", Distance: 10}, {ID: "7"}}
+	if w.Iteration != 7 || len(w.Tanks) != 3 || !reflect.DeepEqual(w.Projectiles, wantP) {
+		t.Error("wrong value", w)
+	}
+}
@@ -0,0 +1,92 @@
+package remote
+
+import (
+	"errors"
+	"strings"
+)
+
+// errors of the TcpClient (see ParseError)
+var (
+	ErrTankNotFound   = errors.New("tank not found")
+	ErrNoAccess       = errors.New("no access to other players units")
+	ErrInvalidCommand = errors.New("invalid command")
+	ErrClosed         = errors.New("connection closed")
+	ErrNoPipeline     = errors.New("pipelining is disabled")
+	ErrPipeline       = errors.New("stream mode is not possible with pipelining")
+)
+
+// ErrNotReady is returned if a tank can't fire or rotate.
+// The status is core.StatusMoving, core.StatusPreparing, core.StatusReloading or core.StatusNoWeapon.
+type ErrNotReady struct {
+	Status string
+}
+
+// Error returns the status.
+func (e ErrNotReady) Error() string {
+	return e.Status
+}
+
+// Error is any other error of the server with the code and name of the protocol (see CodeBadRequest and
+// NameInvalidArgument).
+type Error struct {
+	Code int
+	Name string
+	Msg  string
+}
+
+// Error returns the error text.
+func (e Error) Error() string {
+	return e.Msg
+}
+
+// ParseError converts a server response to an error.
+// Returns nil if the response is not an error ("err: {text}" or "err {code} {name} {text}", see command ErrorCodes).
+// Known errors are returned as ErrTankNotFound, ErrNoAccess, ErrInvalidCommand, ErrClosed or ErrNotReady;
+// all other errors as Error. Without error codes, only the sentinel errors have a code and name.
+func ParseError(resp string) error {
+	if !strings.HasPrefix(resp, "err") {
+		return nil // no error
+	}
+
+	// split "err {code} {name} {text}" or "err: {text}"
+	code, name, msg, ok := splitError(resp)
+	if !ok {
+		msg = strings.TrimPrefix(strings.TrimPrefix(resp, "err"), ": ")
+		code, name = sentinelCode(msg)
+	}
+
+	// client errors
+	if msg == ErrClosed.Error() {
+		return ErrClosed
+	}
+	if strings.HasPrefix(msg, "TcpClient") {
+		return errors.New(msg) // connection error
+	}
+
+	switch name {
+	case NameTankNotFound:
+		return ErrTankNotFound
+	case NameNoAccess:
+		return ErrNoAccess
+	case NameInvalidCommand:
+		return ErrInvalidCommand
+	case NameMoving, NamePreparing, NameReloading, NameNoWeapon:
+		return ErrNotReady{Status: msg}
+	default:
+		return Error{Code: code, Name: name, Msg: msg}
+	}
+}
+
+// sentinelCode returns the code and name of a sentinel error or tank status text (see errorCodes and statusNames).
+// All other texts have no code.
+func sentinelCode(msg string) (int, string) {
+	for err, e := range errorCodes {
+		if err.Error() == msg {
+			return e.Code, e.Name
+		}
+	}
+	if name, ok := statusNames[msg]; ok {
+		return CodeConflict, name
+	}
+	return 0, ""
+}
@@ -0,0 +1,353 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HttpObserver is the player of all HTTP POST requests without token (see ListenHTTP).
+const HttpObserver = "http"
+
+// JSON-RPC error codes (see https://www.jsonrpc.org/specification)
+// All other errors use the codes of the protocol (see CodeBadRequest).
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+)
+
+// rpcRequest is a JSON-RPC 2.0 request.
+// The method is a command and the params are its arguments (see README).
+type rpcRequest struct {
+	JsonRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"` // no id: notification without response
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response or a notification of the server (see Subscribe).
+type rpcResponse struct {
+	JsonRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"` // notification
+	Params  json.RawMessage `json:"params,omitempty"` // notification
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a rpcResponse.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"` // error name (see NameTankNotFound)
+}
+
+//---------------- HTTP ----------------------------------------------------------------------------------------------//
+
+// ListenHTTP runs the gateway for browsers and standard tools (BLOCKING!).
+// It serves the same commands as JSON-RPC 2.0 and the spectator view:
+//
+//	/ws        over WebSocket; each connection is a guest until it joins (see Join and SetOrigins).
+//	/rpc       over HTTP POST; requests with "Authorization: Bearer {token}" are executed as the player
+//	           of the token (see SetTokens), all other requests as observer (see HttpObserver).
+//	/          the spectator page that shows the game in the browser.
+//	/spectate  the world over WebSocket for the spectator page (without joining).
+func (s *Server) ListenHTTP(host, port string) error {
+	fmt.Println("START GATEWAY [" + host + ":" + port + "]")
+	return http.ListenAndServe(net.JoinHostPort(host, port), s.Handler())
+}
+
+// Handler returns the http handler of the gateway (see ListenHTTP).
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", s.serveWebSocket)
+	mux.HandleFunc("/rpc", s.serveRPC)
+	mux.HandleFunc("/", s.serveSpectatorPage)
+	mux.HandleFunc("/spectate", s.serveSpectator)
+	return mux
+}
+
+// SetOrigins sets the allowed origins of browser connections to /ws (e.g. "https://example.com").
+// Browsers send the origin of the page; by default only pages of the gateway itself are allowed.
+// Connections without origin (no browser) are always allowed.
+func (s *Server) SetOrigins(origins ...string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.origins = origins
+}
+
+// checkOrigin returns true if the origin of the request is the gateway itself or allowed (see SetOrigins).
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // no browser
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true // same host
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for _, o := range s.origins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveRPC handles a JSON-RPC request (or a batch of requests) of a HTTP POST.
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, wsMaxMessage))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	owner, ok := s.httpPlayer(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp := s.rpc(&owner, nil, body, nil); resp != nil {
+		_, _ = w.Write(resp)
+	} else {
+		w.WriteHeader(http.StatusNoContent) // only notifications
+	}
+}
+
+// serveWebSocket handles the JSON-RPC requests of a WebSocket connection.
+// The server sends the notification "welcome" with the player first. The connection is a guest until it joins
+// as observer or with a token (see Join); the origin of browsers is checked (see SetOrigins).
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.checkOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	// welcome (a guest until Join, so pages can't take a player slot by just connecting)
+	owner := Guest
+	fmt.Printf("%s from %v\n", Guest, ws.conn.RemoteAddr())
+	welcome, _ := json.Marshal(struct {
+		Player string `json:"player"`
+	}{owner})
+	_ = ws.WriteMessage(notification("welcome", welcome))
+
+	// subscriptions
+	var subs []chan string
+	defer func() {
+		for _, ch := range subs {
+			s.hub.unsubscribe(ch)
+			close(ch) // no more updates after unsubscribe
+		}
+	}()
+	subscribe := func(ch chan string, method string) {
+		subs = append(subs, ch)
+		go func() {
+			for line := range ch {
+				if ws.WriteMessage(notification(method, json.RawMessage(line))) != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	// loop
+	for {
+		msg, err := ws.ReadMessage()
+		if err != nil {
+			break // EXIT
+		}
+		owner = s.player(owner, ws) // see SetSeries
+		if resp := s.rpc(&owner, ws, []byte(msg), subscribe); resp != nil {
+			if ws.WriteMessage(string(resp)) != nil {
+				break // EXIT
+			}
+		}
+	}
+
+	// exit
+	s.leave(owner, ws)
+}
+
+//---------------- JSON-RPC ------------------------------------------------------------------------------------------//
+
+// rpc runs a JSON-RPC request or a batch of requests and returns the json response (nil for notifications).
+// Subscriptions are only possible if subscribe is set (WebSocket).
+// The commands Join and Reconnect change the owner and need the connection (WebSocket).
+func (s *Server) rpc(owner *string, conn io.Closer, msg []byte, subscribe func(ch chan string, method string)) []byte {
+	msg = bytes.TrimSpace(msg)
+
+	// batch of requests
+	if bytes.HasPrefix(msg, []byte("[")) {
+		var list []json.RawMessage
+		if err := json.Unmarshal(msg, &list); err != nil || len(list) == 0 {
+			return marshal(rpcFail(nil, rpcInvalidRequest, "invalid batch", ""))
+		}
+		responses := make([]rpcResponse, 0, len(list))
+		for _, raw := range list {
+			if resp := s.call(owner, conn, raw, subscribe); resp != nil {
+				responses = append(responses, *resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil // only notifications
+		}
+		return marshal(responses)
+	}
+
+	// single request
+	if resp := s.call(owner, conn, msg, subscribe); resp != nil {
+		return marshal(resp)
+	}
+	return nil
+}
+
+// call runs one JSON-RPC request and returns the response (nil for notifications).
+func (s *Server) call(owner *string, conn io.Closer, raw json.RawMessage, subscribe func(ch chan string, method string)) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return rpcFail(nil, rpcParseError, "parse error: "+err.Error(), "")
+	}
+	if req.JsonRPC != "2.0" || req.Method == "" || strings.ContainsAny(req.Method, " \t\r\n") {
+		return rpcFail(req.ID, rpcInvalidRequest, "invalid request", "")
+	}
+
+	// command line
+	args, err := rpcArgs(req.Params)
+	if err != nil {
+		return rpcFail(req.ID, CodeBadRequest, "params: "+err.Error(), NameInvalidArgument)
+	}
+	line := strings.TrimSpace(req.Method + " " + strings.Join(args, " "))
+	if req.Method == "Batch" {
+		list, _ := json.Marshal(args)
+		line = "Batch " + string(list)
+	}
+
+	// execute
+	resp := access(*owner, req.Method) // guests must join first (see SetTokens)
+	if resp == "" {
+		switch req.Method {
+		case "Join", "Reconnect":
+			a1, a2, _, _, _, _ := saveArgs(append([]string{req.Method}, args...))
+			if conn == nil {
+				resp = failErr(ErrInvalidCommand) // HTTP POST
+			} else if req.Method == "Join" {
+				*owner, resp = s.joinSlot(*owner, a1, a2, conn)
+			} else {
+				*owner, resp = s.reconnect(*owner, a1, conn)
+			}
+		case "Subscribe", "SubscribeEvents":
+			resp = rpcSubscribe(s.hub, req.Method, args, subscribe)
+		case "Exit", "ErrorCodes", "RequestIDs":
+			resp = failErr(ErrInvalidCommand) // line protocol only
+		default:
+			resp = s.execute(*owner, line, false)
+		}
+	}
+
+	// response
+	if req.ID == nil {
+		return nil // notification
+	}
+	return rpcResult(req.ID, resp)
+}
+
+// rpcSubscribe subscribes the world ("Subscribe {everyNTicks}") or the events ("SubscribeEvents").
+// The server sends the notifications "world" and "events".
+func rpcSubscribe(h *hub, method string, args []string, subscribe func(ch chan string, method string)) string {
+	if subscribe == nil {
+		return failErr(ErrInvalidCommand) // HTTP POST
+	}
+	if method == "SubscribeEvents" {
+		subscribe(h.subscribeEvents(), "events")
+		return "ok"
+	}
+
+	every, _, _, _, _, _ := saveArgs(append([]string{method}, args...))
+	n, err := strconv.ParseUint(every, 10, 64)
+	if err != nil || n == 0 {
+		return failArg("everyNTicks", "expected a number > 0")
+	}
+	subscribe(h.subscribe(n), "world")
+	return "ok"
+}
+
+// rpcArgs converts the params to command arguments.
+// Strings are used without quotes; numbers, objects and lists as json (e.g. the tree of SetBehavior).
+func rpcArgs(params []json.RawMessage) ([]string, error) {
+	args := make([]string, 0, len(params))
+	for _, p := range params {
+		var str string
+		if err := json.Unmarshal(p, &str); err == nil {
+			args = append(args, str)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, p); err != nil {
+			return nil, err
+		}
+		args = append(args, buf.String())
+	}
+	return args, nil
+}
+
+// rpcResult converts a response of the command handlers.
+// Json responses are returned as json, errors as rpcError and all other responses as string.
+func rpcResult(id json.RawMessage, resp string) *rpcResponse {
+	if code, name, msg, ok := splitError(resp); ok {
+		return rpcFail(id, code, msg, name)
+	}
+
+	result := json.RawMessage(resp)
+	if !(strings.HasPrefix(resp, "{") || strings.HasPrefix(resp, "[")) || !json.Valid(result) {
+		result, _ = json.Marshal(resp)
+	}
+	return &rpcResponse{JsonRPC: "2.0", ID: id, Result: result}
+}
+
+// rpcFail returns an error response.
+func rpcFail(id json.RawMessage, code int, msg, name string) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JsonRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg, Data: name}}
+}
+
+// notification returns a JSON-RPC notification of the server.
+func notification(method string, params json.RawMessage) string {
+	return string(marshal(rpcResponse{JsonRPC: "2.0", Method: method, Params: params}))
+}
+
+// marshal is a helper function and converts the object to json.
+func marshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		fmt.Printf("err: gateway: %v\n", err)
+	}
+	return b
+}
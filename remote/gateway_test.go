@@ -0,0 +1,241 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_serveRPC(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	red, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	red.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(red)
+	srv := httptest.NewServer(NewServer(w).Handler())
+	defer srv.Close()
+
+	post := func(body string) (int, string) {
+		resp, err := http.Post(srv.URL+"/rpc", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"MyName"}`, `{"jsonrpc":"2.0","id":1,"result":"http"}`},
+		{`{"jsonrpc":"2.0","id":"a","method":"TankStatus","params":["x"]}`, `{"jsonrpc":"2.0","id":"a","error":{"code":404,"message":"tank not found","data":"tank_not_found"}}`},
+		{`{"jsonrpc":"2.0","id":2,"method":"Forward","params":["` + red.ID() + `"]}`, `{"jsonrpc":"2.0","id":2,"error":{"code":403,"message":"no access to other players units","data":"no_access"}}`},
+		{`{"jsonrpc":"2.0","id":3,"method":"Batch","params":["MyName","Exit"]}`, `{"jsonrpc":"2.0","id":3,"result":["http","err: invalid command"]}`},
+		{`{"jsonrpc":"2.0","id":4,"method":"Subscribe","params":[1]}`, `{"jsonrpc":"2.0","id":4,"error":{"code":400,"message":"invalid command","data":"invalid_command"}}`},
+		{`{"jsonrpc":"2.0","id":5,"method":"Exit"}`, `{"jsonrpc":"2.0","id":5,"error":{"code":403,"message":"forbidden","data":"forbidden"}}`},
+		{`{"jsonrpc":"1.0","id":6,"method":"MyName"}`, `{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"invalid request"}}`},
+		{`[{"jsonrpc":"2.0","id":7,"method":"MyName"},{"jsonrpc":"2.0","method":"MyName"}]`, `[{"jsonrpc":"2.0","id":7,"result":"http"}]`},
+		{`[]`, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid batch"}}`},
+	}
+	for _, tt := range tests {
+		if code, got := post(tt.body); code != http.StatusOK || got != tt.want {
+			t.Error("wrong value", code, got)
+		}
+	}
+
+	// json result
+	_, got := post(`{"jsonrpc":"2.0","id":1,"method":"TankStatus","params":["` + red.ID() + `"]}`)
+	var resp struct {
+		Result JsonTank `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(got), &resp); err != nil || resp.Result.ID != red.ID() {
+		t.Error("wrong value", got, err)
+	}
+
+	// errors
+	if _, got := post(`{"jsonrpc"`); !strings.Contains(got, `"code":-32700`) {
+		t.Error("wrong value", got)
+	}
+	if code, _ := post(`{"jsonrpc":"2.0","method":"MyName"}`); code != http.StatusNoContent {
+		t.Error("wrong value", code)
+	}
+	if resp, err := http.Get(srv.URL + "/rpc"); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Error("wrong value", resp, err)
+	}
+}
+
+func TestServer_serveWebSocket(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	gw := NewServer(w)
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+
+	// no websocket
+	if resp, err := http.Get(srv.URL + "/ws"); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Error("wrong value", resp, err)
+	}
+
+	// foreign origin
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ws", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Error("wrong value", resp, err)
+	}
+
+	s := NewServer(core.NewWorld(100, 200))
+	s.SetOrigins("https://allowed.example")
+	req.Host = "test"
+	if req.Header.Set("Origin", "https://allowed.example"); !s.checkOrigin(req) {
+		t.Error("origin not allowed")
+	}
+	if req.Header.Set("Origin", "https://test"); !s.checkOrigin(req) {
+		t.Error("same host not allowed")
+	}
+
+	// connect as guest
+	c := dialWebSocket(t, srv.URL)
+	defer c.Close()
+	if msg := c.read(t); msg != `{"jsonrpc":"2.0","method":"welcome","params":{"player":"guest"}}` {
+		t.Error("wrong value", msg)
+	}
+
+	// join the free slot red (without tokens)
+	c.write(t, `{"jsonrpc":"2.0","id":1,"method":"Join","params":["red"]}`)
+	if msg := c.read(t); !strings.HasPrefix(msg, `{"jsonrpc":"2.0","id":1,"result":"ok `) {
+		t.Error("wrong value", msg)
+	}
+	c.write(t, `{"jsonrpc":"2.0","id":1,"method":"MyName"}`)
+	if msg := c.read(t); msg != `{"jsonrpc":"2.0","id":1,"result":"red"}` {
+		t.Error("wrong value", msg)
+	}
+
+	// the slot is taken
+	c2 := dialWebSocket(t, srv.URL)
+	defer c2.Close()
+	_ = c2.read(t) // welcome
+	c2.write(t, `{"jsonrpc":"2.0","id":1,"method":"Join","params":["red"]}`)
+	if msg := c2.read(t); msg != `{"jsonrpc":"2.0","id":1,"error":{"code":409,"message":"slot is taken","data":"slot_taken"}}` {
+		t.Error("wrong value", msg)
+	}
+	c2.write(t, `{"jsonrpc":"2.0","id":1,"method":"Join","params":["observer"]}`)
+	if msg := c2.read(t); msg != `{"jsonrpc":"2.0","id":1,"result":"ok"}` {
+		t.Error("wrong value", msg)
+	}
+	c2.write(t, `{"jsonrpc":"2.0","id":1,"method":"MyName"}`)
+	if msg := c2.read(t); msg != `{"jsonrpc":"2.0","id":1,"result":"observer-1"}` {
+		t.Error("wrong value", msg)
+	}
+
+	// the next TCP client gets the free slot blue
+	if owner, session := gw.join(nil, nil); owner != core.BlueTank || session == "" {
+		t.Error("wrong value", owner, session)
+	}
+
+	// subscribe
+	c.write(t, `{"jsonrpc":"2.0","id":2,"method":"Subscribe","params":[2]}`)
+	if msg := c.read(t); msg != `{"jsonrpc":"2.0","id":2,"result":"ok"}` {
+		t.Error("wrong value", msg)
+	}
+	w.Freeze(false)
+	w.UpdateN(2)
+	var n struct {
+		Method string    `json:"method"`
+		Params JsonWorld `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(c.read(t)), &n); err != nil || n.Method != "world" || n.Params.Iteration != 2 {
+		t.Error("wrong value", n, err)
+	}
+
+	// large message
+	long := strings.Repeat("x", 70000)
+	c.write(t, `{"jsonrpc":"2.0","id":3,"method":"DefineMacro","params":["m","`+long+`"]}`)
+	if msg := c.read(t); !strings.Contains(msg, `"id":3`) || !strings.Contains(msg, `"data":"invalid_script"`) {
+		t.Error("wrong value", msg[:100])
+	}
+}
+
+//---------------- HELPER --------------------------------------------------------------------------------------------//
+
+// testWebSocket is a minimal WebSocket client.
+type testWebSocket struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// dialWebSocket connects to the gateway.
+func dialWebSocket(t *testing.T, url string) *testWebSocket {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: test\r\nOrigin: http://test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("handshake", resp, err)
+	}
+	return &testWebSocket{Conn: conn, r: r}
+}
+
+// write sends a masked text frame.
+func (c *testWebSocket) write(t *testing.T, msg string) {
+	head := []byte{0x81}
+	switch n := len(msg); {
+	case n < 126:
+		head = append(head, 0x80|byte(n))
+	case n <= 0xFFFF:
+		head = append(head, 0x80|126, byte(n>>8), byte(n))
+	default:
+		head = append(head, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(head[2:], uint64(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	payload := []byte(msg)
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	if _, err := c.Write(append(append(head, mask...), payload...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// read returns the next text frame.
+func (c *testWebSocket) read(t *testing.T) string {
+	_ = c.SetReadDeadline(time.Now().Add(time.Second))
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, head); err != nil {
+		t.Fatal(err)
+	}
+	n := uint64(head[1] & 0x7F)
+	if n == 126 {
+		b := make([]byte, 2)
+		_, _ = io.ReadFull(c.r, b)
+		n = uint64(binary.BigEndian.Uint16(b))
+	} else if n == 127 {
+		b := make([]byte, 8)
+		_, _ = io.ReadFull(c.r, b)
+		n = binary.BigEndian.Uint64(b)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return string(payload)
+}
@@ -0,0 +1,87 @@
+package remote
+
+import (
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// Response is the response of an asynchronous command (see TcpClient.CommandAsync).
+type Response struct {
+	Line string // response of the server (see ParseError)
+	Err  error  // connection error
+}
+
+// pipeline sends the commands of a TcpClient with request ids and assigns the responses (see command RequestIDs).
+type pipeline struct {
+	mux     sync.Mutex
+	conn    net.Conn
+	next    uint64                   // last request id
+	pending map[string]chan Response // request id -> waiting request
+	err     error                    // connection error
+}
+
+// newPipeline returns a new pipeline that reads all responses of the connection.
+func newPipeline(conn net.Conn, tp *textproto.Reader) *pipeline {
+	p := &pipeline{
+		conn:    conn,
+		pending: make(map[string]chan Response),
+	}
+	go p.receive(tp)
+	return p
+}
+
+// send writes the command with a new request id and returns the channel of the response.
+func (p *pipeline) send(cmd string) <-chan Response {
+	ch := make(chan Response, 1)
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if p.err != nil {
+		ch <- Response{Err: p.err}
+		return ch // EXIT
+	}
+
+	p.next++
+	id := strconv.FormatUint(p.next, 10)
+	if _, err := p.conn.Write([]byte(fmt.Sprintf("%s %s\r\n", id, cmd))); err != nil {
+		ch <- Response{Err: fmt.Errorf("TcpClient write: %v", err)}
+		return ch // EXIT
+	}
+	p.pending[id] = ch
+	return ch
+}
+
+// receive reads the responses and passes them to the waiting requests until the connection fails (BLOCKING!).
+func (p *pipeline) receive(tp *textproto.Reader) {
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			p.fail(fmt.Errorf("TcpClient read: %v", err))
+			return // EXIT
+		}
+
+		id, resp := splitRequestID(line)
+		p.mux.Lock()
+		if ch, ok := p.pending[id]; ok {
+			ch <- Response{Line: resp}
+			delete(p.pending, id)
+		}
+		p.mux.Unlock()
+	}
+}
+
+// fail returns the error to all waiting and future requests.
+func (p *pipeline) fail(err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.err = err
+	for id, ch := range p.pending {
+		ch <- Response{Err: err}
+		delete(p.pending, id)
+	}
+}
@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/script"
+	"sync"
+)
+
+// MaxScripts is the max. number of user-defined macros per player (see DefineMacro).
+const MaxScripts = 32
+
+// Scripts are the user-defined macros of both players (owner -> name -> program).
+// Each server and each arena match has its own scripts; nil disables user-defined macros.
+type Scripts struct {
+	mux sync.Mutex
+	m   map[string]map[string]*script.Program
+}
+
+// NewScripts returns an empty list of user-defined macros.
+func NewScripts() *Scripts {
+	return &Scripts{
+		m: make(map[string]map[string]*script.Program),
+	}
+}
+
+// define registers the program of a player.
+// An existing program with the same name is replaced.
+func (sc *Scripts) define(owner string, p *script.Program) error {
+	if sc == nil {
+		return Error{Code: CodeForbidden, Name: NameForbidden, Msg: "macros are disabled"}
+	}
+	if owner != core.RedTank && owner != core.BlueTank {
+		return Error{Code: CodeForbidden, Name: NameForbidden, Msg: "only players can define macros"}
+	}
+
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+
+	if sc.m[owner] == nil {
+		sc.m[owner] = make(map[string]*script.Program)
+	}
+	if _, ok := sc.m[owner][p.Name]; !ok && len(sc.m[owner]) >= MaxScripts {
+		return Error{Code: CodeConflict, Name: NameTooManyMacros, Msg: fmt.Sprintf("too many macros (max. %d)", MaxScripts)}
+	}
+	sc.m[owner][p.Name] = p
+	return nil
+}
+
+// lookup returns the program of a player or nil.
+func (sc *Scripts) lookup(owner, name string) *script.Program {
+	if sc == nil {
+		return nil
+	}
+
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+
+	return sc.m[owner][name]
+}
@@ -104,6 +104,7 @@ func (ts *JsonTargets) Set(j string) {
 
 // JsonProjectile is the protocol struct of core.Projectile
 type JsonProjectile struct {
+	ID        string       `json:"id"`
 	Parent    string       `json:"parent"`
 	Pos       JsonPosition `json:"pos"`
 	StartPos  JsonPosition `json:"startPos"`
@@ -123,6 +124,7 @@ func NewJsonProjectile(p *core.Projectile) JsonProjectile {
 		return JsonProjectile{}
 	}
 	jp := JsonProjectile{
+		ID:        p.ID(),
 		Parent:    "", // set later
 		Pos:       NewJsonPosition(p.Pos()),
 		StartPos:  NewJsonPosition(p.StartPos()),
@@ -161,22 +163,26 @@ func (p *JsonProjectile) Set(j string) {
 
 // JsonTank is the protocol struct of core.Tank
 type JsonTank struct {
-	ID          string       `json:"id"`
-	Owner       string       `json:"owner"`
-	Health      int          `json:"health"`
-	Armor       int          `json:"armor"`
-	Speed       int          `json:"speed"`
-	Pos         JsonPosition `json:"pos"`
-	Command     int          `json:"command"`
-	Angle       int          `json:"angle"`
-	IsBlocked   bool         `json:"isBlocked"`
-	ActiveMacro bool         `json:"activeMacro"`
-	Alive       bool         `json:"alive"`
-	Moving      bool         `json:"moving"`
-	LastRotate  uint64       `json:"lastRotate"`
-	Rdy         bool         `json:"rdy"`
-	Status      string       `json:"status"`
-	Weapon      JsonWeapon   `json:"weapon"`
+	ID          string         `json:"id"`
+	Owner       string         `json:"owner"`
+	Health      int            `json:"health"`
+	Armor       int            `json:"armor"`
+	Speed       int            `json:"speed"`
+	Pos         JsonPosition   `json:"pos"`
+	Command     int            `json:"command"`
+	Angle       int            `json:"angle"`
+	IsBlocked   bool           `json:"isBlocked"`
+	ActiveMacro bool           `json:"activeMacro"`
+	Macro       string         `json:"macro"`
+	MacroState  JsonMacroState `json:"macroState"`
+	Strategy    string         `json:"strategy"`
+	Orders      []string       `json:"orders"`
+	Alive       bool           `json:"alive"`
+	Moving      bool           `json:"moving"`
+	LastRotate  uint64         `json:"lastRotate"`
+	Rdy         bool           `json:"rdy"`
+	Status      string         `json:"status"`
+	Weapon      JsonWeapon     `json:"weapon"`
 }
 
 // NewJsonTank convert a core object to a json object
@@ -198,6 +204,10 @@ func NewJsonTank(t *core.Tank) JsonTank {
 		Angle:       t.Angle(),
 		IsBlocked:   t.Blocked(),
 		ActiveMacro: t.ActiveMacro(),
+		Macro:       t.MacroName(),
+		MacroState:  NewJsonMacroState(t.MacroState()),
+		Strategy:    t.Strategy(),
+		Orders:      t.Orders(),
 		Alive:       t.Alive(),
 		Moving:      t.Moving(),
 		LastRotate:  t.LastRotate(),
@@ -223,6 +233,22 @@ func (t *JsonTank) Set(j string) {
 	}
 }
 
+// JsonMacroState is the protocol struct of core.MacroState
+type JsonMacroState struct {
+	Phase    string `json:"phase"`
+	TargetID string `json:"targetID"`
+	Waypoint int    `json:"waypoint"`
+}
+
+// NewJsonMacroState convert a core object to a json object
+func NewJsonMacroState(s core.MacroState) JsonMacroState {
+	return JsonMacroState{
+		Phase:    s.Phase,
+		TargetID: s.TargetID,
+		Waypoint: s.Waypoint,
+	}
+}
+
 //---------------- [6] Weapon ----------------------------------------------------------------------------------------//
 
 // JsonWeapon is the protocol struct of core.Weapon
@@ -298,12 +324,20 @@ type JsonWorld struct {
 	MaxArmor      int              `json:"maxArmor"`
 	MinDamage     int              `json:"minDamage"`
 	MaxDamage     int              `json:"maxDamage"`
+	MaxHealth     int              `json:"maxHealth"`
+	RepairRadius  int              `json:"repairRadius"`
+	RepairDelay   int              `json:"repairDelay"`
 	XWidth        int              `json:"xWidth"`
 	YHeight       int              `json:"yHeight"`
 	ScreenWidth   int              `json:"screenWidth"`
 	ScreenHeight  int              `json:"screenHeight"`
 	Iteration     uint64           `json:"iteration"`
+	Epoch         uint64           `json:"epoch,omitempty"` // map generation (only set by GameStatusSince)
 	Freeze        bool             `json:"freeze"`
+	State         string           `json:"state"`
+	Countdown     uint64           `json:"countdown"`
+	Winner        string           `json:"winner"`
+	Reason        string           `json:"reason"`
 	CashRed       int              `json:"cashRed"`
 	CashBlue      int              `json:"cashBlue"`
 	Tanks         []JsonTank       `json:"tanks"`
@@ -320,11 +354,12 @@ func NewJsonWorld(w *core.World) JsonWorld {
 
 	cRed, cBlue := w.CashStat()
 	uRed, uBlue := w.UnitCount()
-	tanks := make([]JsonTank, 0, 1024)
+	winner, reason := w.Result()
+	tanks := make([]JsonTank, 0, len(w.Tanks()))
 	for _, t := range w.Tanks() {
 		tanks = append(tanks, NewJsonTank(t))
 	}
-	proj := make([]JsonProjectile, 0, 1024)
+	proj := make([]JsonProjectile, 0, len(w.Projectiles()))
 	for _, p := range w.Projectiles() {
 		proj = append(proj, NewJsonProjectile(p))
 	}
@@ -341,12 +376,19 @@ func NewJsonWorld(w *core.World) JsonWorld {
 		MaxArmor:      core.TankMaxArmor,
 		MinDamage:     core.TankMinDamage,
 		MaxDamage:     core.TankMaxDamage,
+		MaxHealth:     core.TankMaxHealth,
+		RepairRadius:  core.BaseRepairRadius,
+		RepairDelay:   core.BaseRepairDelay,
 		XWidth:        w.XWidth(),
 		YHeight:       w.YHeight(),
 		ScreenWidth:   w.ScreenWidth(),
 		ScreenHeight:  w.ScreenHeight(),
 		Iteration:     w.Iteration(),
 		Freeze:        w.IsFrozen(),
+		State:         w.State(),
+		Countdown:     w.Countdown(),
+		Winner:        winner,
+		Reason:        reason,
 		CashRed:       cRed,
 		CashBlue:      cBlue,
 		Tanks:         tanks,
@@ -376,7 +418,7 @@ func (w *JsonWorld) Set(j string) {
 
 // CoreWorld build and returns a new core.World.
 func (w *JsonWorld) CoreWorld() *core.World {
-	world := new(core.World)
+	world := core.NewWorld(w.XWidth, w.YHeight)
 
 	// tank id index
 	tankIndex := make(map[string]*core.Tank)
@@ -394,6 +436,12 @@ func (w *JsonWorld) CoreWorld() *core.World {
 			mco = func(t *core.Tank) {}
 		}
 
+		// orders
+		var orders []core.Order
+		for _, name := range jt.Orders {
+			orders = append(orders, core.Order{Name: name, Run: func(t *core.Tank) core.OrderState { return core.OrderRunning }})
+		}
+
 		// weapon
 		jw := jt.Weapon
 		weapon := new(core.Weapon)
@@ -403,7 +451,7 @@ func (w *JsonWorld) CoreWorld() *core.World {
 		pos := core.Position{X: jt.Pos.X, Xf: jt.Pos.Xf, Y: jt.Pos.Y, Yf: jt.Pos.Yf}
 
 		// init & add tank
-		tank.TestInitialization(world, jt.ID, jt.Owner, weapon, jt.Health, jt.Armor, jt.Speed, pos, jt.Command, jt.Angle, jt.IsBlocked, jt.LastRotate, mco)
+		tank.TestInitialization(world, jt.ID, jt.Owner, weapon, jt.Health, jt.Armor, jt.Speed, pos, jt.Command, jt.Angle, jt.IsBlocked, jt.LastRotate, mco, jt.Strategy, orders, jt.Macro, core.MacroState{Phase: jt.MacroState.Phase, TargetID: jt.MacroState.TargetID, Waypoint: jt.MacroState.Waypoint})
 		tanks[i] = tank
 	}
 
@@ -431,11 +479,103 @@ func (w *JsonWorld) CoreWorld() *core.World {
 		}
 
 		// init & add tank
-		p.TestInitialization(world, parent, cPos, sPos, ePos, jp.Angle, jp.Distance, jp.Speed, jp.Damage, jp.AoeRadius, jp.Collision, exploded)
+		p.TestInitialization(world, jp.ID, parent, cPos, sPos, ePos, jp.Angle, jp.Distance, jp.Speed, jp.Damage, jp.AoeRadius, jp.Collision, exploded)
 		projectiles[i] = p
 	}
 
 	// init world and return
-	world.TestInitialization(w.XWidth, w.YHeight, w.Iteration, tanks, projectiles, w.Freeze, float64(w.CashRed), float64(w.CashBlue))
+	world.TestInitialization(w.XWidth, w.YHeight, w.Iteration, tanks, projectiles, w.Freeze, float64(w.CashRed), float64(w.CashBlue), nil, 0, nil)
 	return world
 }
+
+//---------------- [9] Events (LIST) ---------------------------------------------------------------------------------//
+
+// JsonEvent is the protocol struct of core.Event
+type JsonEvent struct {
+	Seq       uint64       `json:"seq"`
+	Iteration uint64       `json:"iteration"`
+	Type      string       `json:"type"`
+	TankID    string       `json:"tankID"`
+	Owner     string       `json:"owner"`
+	Attacker  string       `json:"attacker"`
+	Damage    int          `json:"damage"`
+	Cash      int          `json:"cash"`
+	Pos       JsonPosition `json:"pos"`
+}
+
+// JsonEvents is the protocol struct of []core.Event
+type JsonEvents []JsonEvent
+
+// NewJsonEvents convert a core object list to a json object
+func NewJsonEvents(es []core.Event) JsonEvents {
+	ret := make(JsonEvents, 0, len(es))
+	for _, e := range es {
+		ret = append(ret, JsonEvent{
+			Seq:       e.Seq,
+			Iteration: e.Iteration,
+			Type:      e.Type,
+			TankID:    e.TankID,
+			Owner:     e.Owner,
+			Attacker:  e.Attacker,
+			Damage:    e.Damage,
+			Cash:      e.Cash,
+			Pos:       NewJsonPosition(e.Pos),
+		})
+	}
+	return ret
+}
+
+// Get returns a json representation of this object
+func (es *JsonEvents) Get() string {
+	b, err := json.Marshal(es)
+	if err != nil || es == nil {
+		fmt.Printf("err: JsonEvents: %v\n", err)
+	}
+	return string(b)
+}
+
+// Set parse a json string and update the inner variables of this object
+func (es *JsonEvents) Set(j string) {
+	if err := json.Unmarshal([]byte(j), &es); err != nil {
+		fmt.Printf("err: JsonEvents: %v\n", err)
+	}
+}
+
+//---------------- [10] Game result ----------------------------------------------------------------------------------//
+
+// JsonGameResult is the response of GameResult (see core.World.State and core.World.Result)
+type JsonGameResult struct {
+	State  string `json:"state"`
+	Winner string `json:"winner"`
+	Reason string `json:"reason"`
+}
+
+// NewJsonGameResult convert a core object to a json object
+func NewJsonGameResult(w *core.World) JsonGameResult {
+	if w == nil {
+		return JsonGameResult{}
+	}
+
+	winner, reason := w.Result()
+	return JsonGameResult{
+		State:  w.State(),
+		Winner: winner,
+		Reason: reason,
+	}
+}
+
+// Get returns a json representation of this object
+func (r *JsonGameResult) Get() string {
+	b, err := json.Marshal(r)
+	if err != nil || r == nil {
+		fmt.Printf("err: JsonGameResult: %v\n", err)
+	}
+	return string(b)
+}
+
+// Set parse a json string and update the inner variables of this object
+func (r *JsonGameResult) Set(j string) {
+	if err := json.Unmarshal([]byte(j), &r); err != nil {
+		fmt.Printf("err: JsonGameResult: %v\n", err)
+	}
+}
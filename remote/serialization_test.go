@@ -81,9 +81,9 @@ func TestJsonProjectile_Changes(t *testing.T) {
 	// detect struct changes
 	pos := core.NewPosition(9, 8)
 	o := core.NewProjectile(nil, nil, pos, 11, 22, 33, 44, 55, false) // NewProjectile
-	cs := "&core.Projectile{world:(*core.World)(nil), parent:(*core.Tank)(nil), pos:core.Position{X:9, Xf:9, Y:8, Yf:8}, startPos:core.Position{X:9, Xf:9, Y:8, Yf:8}, endPos:core.Position{X:13, Xf:13.197797898283973, Y:-14, Yf:-13.59579803584861}, angle:11, distance:22, speed:33, damage:44, aoeRadius:55, collision:false, exploded:0x0}"
+	cs := "&core.Projectile{world:(*core.World)(nil), id:\"9999\", parent:(*core.Tank)(nil), pos:core.Position{X:9, Xf:9, Y:8, Yf:8}, startPos:core.Position{X:9, Xf:9, Y:8, Yf:8}, endPos:core.Position{X:13, Xf:13.197797898283973, Y:-14, Yf:-13.59579803584861}, angle:11, distance:22, speed:33, damage:44, aoeRadius:55, collision:false, exploded:0x0}"
 
-	if s := fmt.Sprintf("%#v", o); s != cs {
+	if s := fixJsonStrings(fmt.Sprintf("%#v", o)); s != cs {
 		println(cs)
 		println(s)
 		t.Fatal(s)
@@ -98,7 +98,7 @@ func TestJsonProjectile(t *testing.T) {
 	newO.Set(str)                                                                    // parse
 
 	// check
-	if newO.Pos.X != 1 || newO.Pos.Y != 2 || newO.Angle != 3 || newO.Distance != 4 || newO.Speed != 5 || newO.Damage != 6 || newO.AoeRadius != 7 || newO.Collision != true {
+	if newO.ID != org.ID() || newO.Pos.X != 1 || newO.Pos.Y != 2 || newO.Angle != 3 || newO.Distance != 4 || newO.Speed != 5 || newO.Damage != 6 || newO.AoeRadius != 7 || newO.Collision != true {
 		t.Error("wrong value")
 	}
 	// test invalid input
@@ -113,7 +113,7 @@ func TestJsonProjectile(t *testing.T) {
 func TestJsonTank_Changes(t *testing.T) {
 	// detect struct changes
 	o, _ := core.NewTank(nil, core.RedTank, 11, 22, core.WeaponCannon) // NewTank
-	cs := "&core.Tank{world:(*core.World)(nil), id:\"9999\", owner:\"red\", weapon:(*core.Weapon)(0x1010101010), health:100, armor:11, speed:70, pos:core.Position{X:0, Xf:0, Y:0, Yf:0}, command:0, angle:180, isBlocked:false, lastRotate:0x0, macro:(func(*core.Tank))(nil)}"
+	cs := "&core.Tank{world:(*core.World)(nil), id:\"9999\", owner:\"red\", weapon:(*core.Weapon)(0x1010101010), health:100, armor:11, speed:70, pos:core.Position{X:0, Xf:0, Y:0, Yf:0}, command:0, angle:180, isBlocked:false, lastRotate:0x0, macro:(func(*core.Tank))(nil), macroName:\"\", macroState:core.MacroState{Phase:\"\", TargetID:\"\", Waypoint:0}, strategy:\"\", orders:[]core.Order(nil)}"
 
 	s := fmt.Sprintf("%#v", o)
 	s = fixJsonStrings(s)
@@ -181,9 +181,9 @@ func TestJsonWeapon(t *testing.T) {
 func TestJsonWorld_Changes(t *testing.T) {
 	// detect struct changes
 	o := core.NewWorld(33, 44) // NewWorld
-	cs := "&core.World{xWidth:33, yHeight:44, iteration:0x0, tanks:[]*core.Tank{}, projectiles:[]*core.Projectile{}, freeze:false, cashRed:0, cashBlue:0}"
+	cs := "&core.World{xWidth:33, yHeight:44, iteration:0x0, tanks:[]*core.Tank{}, projectiles:[]*core.Projectile{}, freeze:false, speed:1, cashRed:0, cashBlue:0, state:\"\", countdown:0x0, winner:\"\", reason:\"\", contested:false, events:[]core.Event{}, eventSeq:0x0, onUpdate:(func(*core.World))(nil), mux:(*sync.Mutex)(0x1010101010), rnd:(*core.worldRand)(0x1010101010)}"
 
-	if s := fmt.Sprintf("%#v", o); s != cs {
+	if s := fixJsonStrings(fmt.Sprintf("%#v", o)); s != cs {
 		println(cs)
 		println(s)
 		t.Fatal(s)
@@ -228,6 +228,7 @@ func TestJsonWorld_CoreWorld(t *testing.T) {
 	nt1, _ := core.NewTank(w, "owner 1", 22, 33, core.WeaponCannon)
 	nt1.SetPosition(core.NewPosition(234, 567), core.Northwest)
 	nt1.SetMacro(func(t *core.Tank) {})
+	nt1.SetStrategy(core.StrategyLowestHealth)
 	w.AddTank(nt1)
 
 	nt2, _ := core.NewTank(w, "owner 1", 33, 22, core.WeaponArtillery)
@@ -259,6 +260,12 @@ func TestJsonWorld_CoreWorld(t *testing.T) {
 	}
 	nt1.SetMacro(nil) // disable macro for next compare
 
+	// the events are not part of the world status (see Events)
+	cashRed, cashBlue := w.CashStat()
+	w.TestInitialization(w.XWidth(), w.YHeight(), w.Iteration(), w.Tanks(), w.Projectiles(), w.IsFrozen(), float64(cashRed), float64(cashBlue), nil, 0, nil)
+	w.SetSeed(1) // the random number generator is not part of the world status (see SetSeed)
+	w2.SetSeed(1)
+
 	// compare
 	if !reflect.DeepEqual(w, w2) || len(w.Projectiles()) < 2 || len(w.Tanks()) < 2 {
 		t.Error("wrong value")
@@ -287,9 +294,49 @@ func fixJsonStrings(s string) string {
 	reg := regexp.MustCompile(`weapon:\(\*core\.Weapon\)\(0x.+?\)`)
 	s = reg.ReplaceAllString(s, "weapon:(*core.Weapon)(0x1010101010)")
 
-	// fix  `id:"1235", owner:`
-	reg = regexp.MustCompile(`id:".+?", owner:`)
-	s = reg.ReplaceAllString(s, `id:"9999", owner:`)
+	// fix  `id:"1235", owner:` and `id:"1236", parent:`
+	reg = regexp.MustCompile(`id:".+?", (owner|parent):`)
+	s = reg.ReplaceAllString(s, `id:"9999", $1:`)
+
+	// fix  `mux:(*sync.Mutex)(0xc000632000)`
+	reg = regexp.MustCompile(`mux:\(\*sync\.Mutex\)\(0x.+?\)`)
+	s = reg.ReplaceAllString(s, "mux:(*sync.Mutex)(0x1010101010)")
+
+	// fix  `rnd:(*core.worldRand)(0xc000632000)`
+	reg = regexp.MustCompile(`rnd:\(\*core\.worldRand\)\(0x.+?\)`)
+	s = reg.ReplaceAllString(s, "rnd:(*core.worldRand)(0x1010101010)")
 
 	return s
 }
+
+//---------------- Events --------------------------------------------------------------------------------------------//
+
+func TestJsonEvents_Changes(t *testing.T) {
+	// detect struct changes
+	o := &core.Event{Seq: 1, Iteration: 2, Type: "t", TankID: "i", Owner: "o", Attacker: "a", Damage: 3, Cash: 4}
+	cs := "&core.Event{Seq:0x1, Iteration:0x2, Type:\"t\", TankID:\"i\", Owner:\"o\", Attacker:\"a\", Damage:3, Cash:4, Pos:core.Position{X:0, Xf:0, Y:0, Yf:0}}"
+
+	if s := fmt.Sprintf("%#v", o); s != cs {
+		println(cs)
+		println(s)
+		t.Fatal(s)
+	}
+}
+
+func TestJsonEvents(t *testing.T) {
+	org := []core.Event{{Seq: 7, Type: core.EventHit, TankID: "1", Attacker: "2", Damage: 33, Pos: core.NewPosition(5, 6)}}
+	obj := NewJsonEvents(org) // JSON Object
+	str := obj.Get()          // json string
+	newO := JsonEvents{}      // NEW JSON Object
+	newO.Set(str)             // parse
+
+	// check
+	if !reflect.DeepEqual(obj, newO) || newO[0].Damage != 33 || newO[0].Pos.X != 5 {
+		t.Error("wrong value", newO)
+	}
+	// test invalid input
+	newO.Set("")
+	NewJsonEvents(nil)
+	var nilObj *JsonEvents
+	nilObj.Get() // test nil
+}
@@ -0,0 +1,183 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"io"
+	"time"
+)
+
+// JsonSeries is the response of the command Series (see Server.SetSeries).
+// The score belongs to the connections and follows them if the sides are swapped.
+type JsonSeries struct {
+	Game      int    `json:"game"`      // the current game (starts with 1)
+	Games     int    `json:"games"`     // best of n games
+	Swap      bool   `json:"swap"`      // the sides are swapped after every game
+	ScoreRed  int    `json:"scoreRed"`  // won games of the current player red
+	ScoreBlue int    `json:"scoreBlue"` // won games of the current player blue
+	Over      bool   `json:"over"`      // the series is over
+	Winner    string `json:"winner"`    // winner of the series (current side; empty while running or for a draw)
+}
+
+// Get returns a json representation of this object
+func (js *JsonSeries) Get() string {
+	b, err := json.Marshal(js)
+	if err != nil || js == nil {
+		fmt.Printf("err: JsonSeries: %v\n", err)
+	}
+	return string(b)
+}
+
+// Set parse a json string and update the inner variables of this object
+func (js *JsonSeries) Set(j string) {
+	if err := json.Unmarshal([]byte(j), &js); err != nil {
+		fmt.Printf("err: JsonSeries: %v\n", err)
+	}
+}
+
+// SetSeries sets the number of games of a best-of-n series (default 1).
+// After a game is over, the map is loaded again (see LoadMap) after the delay and the next game starts with the
+// same connections. With swap, red and blue change their sides (incl. the reserved slots, see SetTokens).
+// The series is over as soon as a player has won the majority of the games or has forfeited.
+func (s *Server) SetSeries(games int, swap bool, delay time.Duration) error {
+	if games < 1 {
+		return errors.New("expected at least one game")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.games, s.swap, s.delay = games, swap, delay
+	return nil
+}
+
+// series is the command Series and returns the score of the series.
+func (s *Server) series() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.count() // the update may not have been counted yet
+	red, blue := s.score()
+	js := JsonSeries{
+		Game:      s.game,
+		Games:     s.games,
+		Swap:      s.swap,
+		ScoreRed:  red,
+		ScoreBlue: blue,
+		Over:      s.decided,
+	}
+	if s.decided && red > blue {
+		js.Winner = core.RedTank
+	} else if s.decided && blue > red {
+		js.Winner = core.BlueTank
+	}
+	return js.Get()
+}
+
+// update is called after every update of the world (see core.World.SetOnUpdate).
+// It pushes the world to the subscribers and counts the result of a finished game.
+func (s *Server) update(w *core.World) {
+	s.hub.update(w)
+	if w.State() == core.StateFinished {
+		go s.gameOver() // the world is locked
+	}
+}
+
+// gameOver counts the result of the finished game (see count).
+func (s *Server) gameOver() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.count()
+}
+
+// count counts the result of a finished game and starts the next game of the series after the delay (call with lock).
+func (s *Server) count() {
+	s.world.Lock()
+	state := s.world.State()
+	winner, reason := s.world.Result()
+	s.world.Unlock()
+	if s.over || state != core.StateFinished {
+		return // already counted or a new map is loaded
+	}
+	s.over = true
+
+	// score
+	if sess := s.sessions[winner]; sess != nil {
+		sess.wins++
+	}
+	red, blue := s.score()
+	fmt.Printf("GAME %d OVER: winner '%s' (%s) [%d:%d]\n", s.game, winner, reason, red, blue)
+
+	// next game
+	if reason == core.ReasonForfeit || 2*red > s.games || 2*blue > s.games || s.game >= s.games {
+		s.decided = true
+		if s.games > 1 {
+			fmt.Printf("SERIES OVER\n")
+		}
+		return
+	}
+	time.AfterFunc(s.delay, s.nextGame)
+}
+
+// nextGame loads the map for the next game of the series (see SetSeries).
+func (s *Server) nextGame() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if !s.over {
+		return // an admin has already loaded a new map (see Restart)
+	}
+	if s.swap {
+		s.swapSides()
+	}
+	s.game++
+	fmt.Printf("START GAME %d OF %d\n", s.game, s.games)
+	if err := s.loadMap(s.mapName); err != nil {
+		fmt.Printf("err: next game: %v\n", err)
+	}
+}
+
+// swapSides swaps the player slots of red and blue with their connections and tokens (call with lock).
+func (s *Server) swapSides() {
+	s.sessions[core.RedTank], s.sessions[core.BlueTank] = s.sessions[core.BlueTank], s.sessions[core.RedTank]
+	if s.tokens != nil {
+		s.tokens[core.RedTank], s.tokens[core.BlueTank] = s.tokens[core.BlueTank], s.tokens[core.RedTank]
+	}
+	fmt.Printf("SWAP SIDES\n")
+}
+
+// score returns the won games of the current players red and blue (call with lock).
+func (s *Server) score() (red, blue int) {
+	if sess := s.sessions[core.RedTank]; sess != nil {
+		red = sess.wins
+	}
+	if sess := s.sessions[core.BlueTank]; sess != nil {
+		blue = sess.wins
+	}
+	return red, blue
+}
+
+// player returns the current player of the connection (see seat).
+func (s *Server) player(owner string, conn io.Closer) string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.seat(owner, conn)
+}
+
+// seat returns the current player of the connection, because the sides can be swapped (call with lock).
+// Observers and guests are returned unchanged.
+func (s *Server) seat(owner string, conn io.Closer) string {
+	if owner != core.RedTank && owner != core.BlueTank {
+		return owner
+	}
+	for player, sess := range s.sessions {
+		if sess != nil && sess.conn == conn {
+			return player
+		}
+	}
+	return owner
+}
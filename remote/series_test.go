@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+	"time"
+)
+
+func TestServer_SetSeries(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	s := NewServer(w)
+	if err := s.SetSeries(0, false, 0); err == nil {
+		t.Error("wrong value", err)
+	}
+	if err := s.SetSeries(3, true, 30*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadMap("test"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.ListenTCP("localhost", "3342")
+	}()
+	time.Sleep(400 * time.Millisecond) // wait for server
+
+	red, _ := NewTcpClient("localhost", "3342")
+	blue, err := NewTcpClient("localhost", "3342")
+	if err != nil || red == nil {
+		t.Fatal(err)
+	}
+	if js, _ := red.Series(); js != (JsonSeries{Game: 1, Games: 3, Swap: true}) {
+		t.Error("wrong value", js)
+	}
+
+	// win is a helper function and destroys all units of the loser
+	win := func(winner, loser string) {
+		w.Update()
+		w.Lock()
+		w.Clear(loser)
+		w.Unlock()
+		w.Update()
+		if _, r, _ := result(w); r != winner {
+			t.Error("wrong value", r)
+		}
+		time.Sleep(100 * time.Millisecond) // wait for the next game
+	}
+
+	// game 1: red wins and the sides are swapped
+	jw := new(JsonWorld)
+	if err := red.GameStatusSince(jw); err != nil || jw.Epoch != 1 {
+		t.Error("wrong value", jw.Epoch, err)
+	}
+	win(core.RedTank, core.BlueTank)
+	if me, _ := red.MyName(); me != core.BlueTank {
+		t.Error("wrong value", me)
+	}
+	if me, _ := blue.MyName(); me != core.RedTank {
+		t.Error("wrong value", me)
+	}
+	if js, _ := red.Series(); js != (JsonSeries{Game: 2, Games: 3, Swap: true, ScoreBlue: 1}) {
+		t.Error("wrong value", js)
+	}
+	state, _, i := result(w)
+	if r, b := unitCount(w); state != core.StateRunning || r == 0 || b == 0 || i != 0 {
+		t.Error("wrong value", state, r, b, i)
+	}
+
+	// the delta of the old game is a full resync with the new map (see history.reset)
+	if err := red.GameStatusSince(jw); err != nil || jw.Epoch != 2 {
+		t.Error("wrong value", jw.Epoch, err)
+	}
+	w.Lock()
+	full := NewJsonWorld(w)
+	w.Unlock()
+	if len(jw.Tanks) != len(full.Tanks) || jw.Tanks[0].ID != full.Tanks[0].ID {
+		t.Error("wrong merge", jw.Tanks, full.Tanks)
+	}
+
+	// game 2: the first client wins again as blue (2 of 3)
+	win(core.BlueTank, core.RedTank)
+	if js, _ := blue.Series(); js != (JsonSeries{Game: 2, Games: 3, Swap: true, ScoreBlue: 2, Over: true, Winner: core.BlueTank}) {
+		t.Error("wrong value", js)
+	}
+	if me, _ := red.MyName(); me != core.BlueTank {
+		t.Error("wrong value", me)
+	}
+	if state, _, _ := result(w); state != core.StateFinished {
+		t.Error("wrong value", state)
+	}
+	if err := ParseError(red.Command("Stop 1")); err == nil || err.Error() != "game is over" {
+		t.Error("wrong value", err)
+	}
+	_ = red.Close()
+	_ = blue.Close()
+}
+
+// result returns the state, the winner and the iteration of the world with lock, because the next game of the
+// series is loaded concurrently.
+func result(w *core.World) (state, winner string, iteration uint64) {
+	w.Lock()
+	defer w.Unlock()
+
+	winner, _ = w.Result()
+	return w.State(), winner, w.Iteration()
+}
@@ -4,24 +4,79 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/SchnorcherSepp/TankWars/core"
+	"io"
 	"log"
 	"net"
 	"net/textproto"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-// RunServer runs a server (BLOCKING!).
-// The server receives commands from the clients and implements them in "World".
+// Server serves one world to the clients of all transports (see ListenTCP and ListenHTTP).
 // The first connecting client controls player red.
 // The second connecting client controls player blue.
+// All further clients are observers (WebSocket clients join a free player slot instead, see joinSlot).
+// With reserved slots, all clients must join with a token instead (see SetTokens).
+// Players can reclaim their slot after a disconnect (see Reconnect and SetDisconnectPolicy).
+// The server can play a series of games with the same connections (see SetSeries).
+type Server struct {
+	world      *core.World
+	hub        *hub     // see Subscribe
+	scripts    *Scripts // see DefineMacro
+	mux        sync.Mutex
+	clients    uint64              // number of joined clients
+	observers  uint64              // number of observers
+	tokens     map[string]string   // player -> token (see SetTokens)
+	adminToken string              // admin token (see SetAdminToken)
+	sessions   map[string]*session // player -> session (see Reconnect)
+	started    bool                // both players have joined
+	paused     bool                // paused by an admin (see Pause)
+	pause      bool                // freeze the world while a player is disconnected (see SetDisconnectPolicy)
+	forfeit    time.Duration       // max. time to reconnect (see SetDisconnectPolicy)
+	mapName    string              // the map of the game (see LoadMap)
+	countdown  time.Duration       // countdown before the game starts (see SetCountdown)
+	games      int                 // number of games of the series (see SetSeries)
+	swap       bool                // swap the sides after every game (see SetSeries)
+	delay      time.Duration       // time between two games of the series (see SetSeries)
+	game       int                 // the current game of the series (starts with 1)
+	over       bool                // the current game is over and counted (see count)
+	decided    bool                // the series is over (see count)
+	origins    []string            // allowed origins of browser connections (see SetOrigins)
+}
+
+// NewServer returns a new server for the world.
+// The world is waiting until both players have joined (see core.World.Wait).
+func NewServer(world *core.World) *Server {
+	world.Wait() // wait for all player
+	s := &Server{
+		world:    world,
+		hub:      newHub(world),
+		scripts:  NewScripts(),
+		sessions: make(map[string]*session),
+		games:    1,
+		game:     1,
+	}
+	world.SetOnUpdate(s.update)
+	return s
+}
+
+// RunServer runs a server (BLOCKING!).
+// The server receives commands from the clients and implements them in "World" (see Server).
 func RunServer(host, port string, world *core.World) {
-	world.Freeze(true) // wait for all player
+	if err := NewServer(world).ListenTCP(host, port); err != nil {
+		log.Fatalf("RunServer: %v\n", err)
+	}
+}
 
+// ListenTCP accepts the clients of the line-based protocol (BLOCKING!).
+func (s *Server) ListenTCP(host, port string) error {
 	// Listen for incoming connections.
 	l, err := net.Listen("tcp", host+":"+port)
 	if err != nil {
-		log.Fatalf("RunServer: %v\n", err)
+		return err
 	}
 
 	// Close the listener when the application closes.
@@ -30,7 +85,7 @@ func RunServer(host, port string, world *core.World) {
 	}(l)
 
 	fmt.Println("START SERVER [" + host + ":" + port + "]")
-	for i := uint64(1); true; i++ {
+	for {
 		// wait for incoming connection
 		conn, err := l.Accept()
 		if err != nil {
@@ -39,33 +94,73 @@ func RunServer(host, port string, world *core.World) {
 		}
 
 		// Handle connections in a new goroutine.
-		if i == 1 {
-			// player 1: red
-			owner := core.RedTank
-			go handleRequest(conn, world, owner)
-			fmt.Printf("player %d (%s) from %v\n", i, owner, conn.RemoteAddr())
-
-		} else if i == 2 {
-			// player 2: blue
-			owner := core.BlueTank
-			go handleRequest(conn, world, owner)
-			fmt.Printf("player %d (%s) from %v\n", i, owner, conn.RemoteAddr())
-
-			// START GAME with player 2!!
-			world.Freeze(false)
-			fmt.Printf("START GAME\n")
-
-		} else {
-			// server full
-			owner := fmt.Sprintf("observer-%d", i-2)
-			go handleRequest(conn, world, owner)
-			fmt.Printf("%s from %v\n", owner, conn.RemoteAddr())
+		owner, session := s.join(conn.RemoteAddr(), conn)
+		go handleRequest(conn, s, owner, session)
+	}
+}
+
+// join returns the player of a new client (red, blue or observer-n) and the session token of players.
+// The clients get the free player slots in order (WebSocket clients can claim them with Join, see joinSlot).
+// The game starts with the second player.
+// With reserved slots, all clients are guests until they join (see SetTokens).
+func (s *Server) join(addr net.Addr, conn io.Closer) (string, string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.tokens != nil {
+		fmt.Printf("%s from %v\n", Guest, addr)
+		return Guest, ""
+	}
+
+	s.clients++
+	i := s.clients
+	for _, player := range []string{core.RedTank, core.BlueTank} {
+		if s.sessions[player] != nil {
+			continue // the slot is taken (also by a disconnected player, see Reconnect)
 		}
+		fmt.Printf("player %d (%s) from %v\n", i, player, addr)
+		session := s.claim(player, conn)
+
+		// START GAME with player 2!!
+		if s.sessions[core.RedTank] != nil && s.sessions[core.BlueTank] != nil {
+			s.start()
+		}
+		return player, session
+	}
+
+	// server full
+	s.observers++
+	owner := fmt.Sprintf("observer-%d", s.observers)
+	fmt.Printf("%s from %v\n", owner, addr)
+	return owner, ""
+}
+
+// execute runs one command line that is available on all transports and returns the response.
+// With codes, the responses of a batch contain the error codes (see Batch).
+func (s *Server) execute(owner, line string, codes bool) string {
+	args := strings.Split(strings.TrimSpace(line), " ")
+	switch args[0] {
+	case "Batch":
+		return Batch(s.world, s.scripts, owner, strings.Join(restArgs(args, 1), " "), codes)
+	case "Series":
+		return s.series()
+	case "Pause", "Resume", "SetSpeed", "Restart", "LoadMap", "SetCash":
+		return s.admin(args) // see access
+	}
+
+	// the world is updated concurrently (Batch locks itself)
+	s.world.Lock()
+	defer s.world.Unlock()
+
+	if args[0] == "GameStatusSince" {
+		iteration, epoch, _, _, _, _ := saveArgs(args)
+		return s.hub.history.since(s.world, iteration, epoch)
 	}
+	return Execute(s.world, s.scripts, owner, line)
 }
 
 // Handles incoming requests.
-func handleRequest(conn net.Conn, w *core.World, owner string) {
+func handleRequest(conn net.Conn, s *Server, owner, session string) {
 
 	// prepare line reader
 	reader := bufio.NewReader(conn)
@@ -77,80 +172,200 @@ func handleRequest(conn net.Conn, w *core.World, owner string) {
 	}(conn)
 
 	// welcome
-	_, _ = conn.Write([]byte("welcome player " + owner + "\n"))
+	_, _ = conn.Write([]byte(strings.TrimSpace("welcome player "+owner+" "+session) + "\n"))
+
+	// connection settings
+	var codes bool // see WithoutErrorCode
+	var ids bool   // see RequestIDs
 
 	// loop
+loop:
 	for {
 		// read one line (ended with \n or \r\n)
 		line, err := tp.ReadLine()
 		if err != nil {
 			break // EXIT
 		}
-
-		// trim line and split args
+		var id string
+		if ids {
+			id, line = splitRequestID(line)
+		}
 		args := strings.Split(strings.TrimSpace(line), " ")
+		owner = s.player(owner, conn) // see SetSeries
 
-		// extract com
-		var com string
-		if len(args) > 0 {
-			com = args[0]
+		// guests must join first (see SetTokens)
+		if resp := access(owner, args[0]); resp != "" {
+			respond(conn, id, resp, codes)
+			continue
 		}
 
-		// CHECK COMMANDS
-		switch com {
+		// connection commands
+		var resp string
+		switch args[0] {
+		case "Join":
+			player, token, _, _, _, _ := saveArgs(args)
+			owner, resp = s.joinSlot(owner, player, token, conn)
+		case "Reconnect":
+			token, _, _, _, _, _ := saveArgs(args)
+			owner, resp = s.reconnect(owner, token, conn)
 		case "Exit":
 			println("EXIT by player", owner)
 			os.Exit(0)
-		case "MyName":
-			comResponse(conn, MyName(owner))
-		case "GameStatus":
-			comResponse(conn, GameStatus(w))
-		case "TankStatus":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, TankStatus(w, tankID))
-		case "CloseTargets":
-			tankID, filter1, filter2, filter3, filter4, filter5 := saveArgs(args)
-			comResponse(conn, CloseTargets(w, tankID, filter1, filter2, filter3, filter4, filter5))
-		case "PossibleTargets":
-			tankID, filter1, filter2, filter3, filter4, filter5 := saveArgs(args)
-			comResponse(conn, PossibleTargets(w, tankID, filter1, filter2, filter3, filter4, filter5))
-		case "BuyTank":
-			armor, damage, weapon, _, _, _ := saveArgs(args)
-			comResponse(conn, BuyTank(w, owner, armor, damage, weapon))
-		case "Fire":
-			tankID, angle, distance, _, _, _ := saveArgs(args)
-			comResponse(conn, Fire(w, owner, tankID, angle, distance))
-		case "FireAt":
-			tankID, x, y, _, _, _ := saveArgs(args)
-			comResponse(conn, FireAt(w, owner, tankID, x, y))
-		case "Forward":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, Forward(w, owner, tankID))
-		case "Backward":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, Backward(w, owner, tankID))
-		case "Stop":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, Stop(w, owner, tankID))
-		case "Left":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, Left(w, owner, tankID))
-		case "Right":
-			tankID, _, _, _, _, _ := saveArgs(args)
-			comResponse(conn, Right(w, owner, tankID))
-		case "SetMacroMoveTo":
-			tankID, x, y, _, _, _ := saveArgs(args)
-			comResponse(conn, SetMacroMoveTo(w, owner, tankID, x, y))
-		case "SetMacro":
-			tankID, macro, _, _, _, _ := saveArgs(args)
-			comResponse(conn, SetMacro(w, owner, tankID, macro))
+		case "Subscribe":
+			every, _, _, _, _, _ := saveArgs(args)
+			n, err := strconv.ParseUint(every, 10, 64)
+			if err != nil || n == 0 {
+				resp = failArg("everyNTicks", "expected a number > 0")
+				break
+			}
+			comResponse(conn, withRequestID(id, "ok"))
+			stream(conn, tp, s.hub, s.hub.subscribe(n)) // blocking
+			break loop                                  // EXIT
+		case "SubscribeEvents":
+			comResponse(conn, withRequestID(id, "ok"))
+			stream(conn, tp, s.hub, s.hub.subscribeEvents()) // blocking
+			break loop                                       // EXIT
+		case "ErrorCodes":
+			mode, _, _, _, _, _ := saveArgs(args)
+			if mode == "on" || mode == "off" {
+				codes = mode == "on"
+				resp = "ok"
+			} else {
+				resp = failArg("mode", "expected on or off")
+			}
+		case "RequestIDs":
+			mode, _, _, _, _, _ := saveArgs(args)
+			if mode == "on" || mode == "off" {
+				ids = mode == "on"
+				resp = "ok"
+			} else {
+				resp = failArg("mode", "expected on or off")
+			}
 		default:
-			comResponse(conn, "err: invalid command")
+			resp = s.execute(owner, line, codes)
 		}
+
+		// response
+		respond(conn, id, resp, codes)
 	}
 
 	// exit
-	fmt.Printf("player %s has left\n", owner)
+	s.leave(owner, conn)
+}
+
+// readCommands are the commands that are still possible after the game is over (see core.StateFinished).
+// The commands of a Batch are checked one by one.
+var readCommands = map[string]bool{
+	"MyName":          true,
+	"GameStatus":      true,
+	"GameResult":      true,
+	"Events":          true,
+	"TankStatus":      true,
+	"CloseTargets":    true,
+	"PossibleTargets": true,
+}
+
+// Execute runs one command line of a player and returns the response (see README).
+// The user-defined macros of the players are stored in the scripts (see DefineMacro).
+// It is used by all connections and the arena; the command Exit is handled by the server.
+// After the game is over, only the readCommands are possible.
+func Execute(w *core.World, sc *Scripts, owner, line string) string {
+	// trim line and split args
+	args := strings.Split(strings.TrimSpace(line), " ")
+
+	// extract com
+	var com string
+	if len(args) > 0 {
+		com = args[0]
+	}
+
+	// game over
+	if w != nil && w.State() == core.StateFinished && !readCommands[com] && com != "Batch" {
+		return fail(CodeConflict, NameGameOver, "game is over")
+	}
+
+	// CHECK COMMANDS
+	switch com {
+	case "MyName":
+		return MyName(owner)
+	case "GameStatus":
+		return GameStatus(w)
+	case "GameResult":
+		return GameResult(w)
+	case "Events":
+		sinceSeq, _, _, _, _, _ := saveArgs(args)
+		return Events(w, sinceSeq)
+	case "TankStatus":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return TankStatus(w, tankID)
+	case "CloseTargets":
+		tankID, filter1, filter2, filter3, filter4, filter5 := saveArgs(args)
+		return CloseTargets(w, tankID, filter1, filter2, filter3, filter4, filter5)
+	case "PossibleTargets":
+		tankID, filter1, filter2, filter3, filter4, filter5 := saveArgs(args)
+		return PossibleTargets(w, tankID, filter1, filter2, filter3, filter4, filter5)
+	case "BuyTank":
+		armor, damage, weapon, _, _, _ := saveArgs(args)
+		return BuyTank(w, owner, armor, damage, weapon)
+	case "Fire":
+		tankID, angle, distance, _, _, _ := saveArgs(args)
+		return Fire(w, owner, tankID, angle, distance)
+	case "FireAt":
+		tankID, x, y, _, _, _ := saveArgs(args)
+		return FireAt(w, owner, tankID, x, y)
+	case "Forward":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Forward(w, owner, tankID)
+	case "Backward":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Backward(w, owner, tankID)
+	case "Stop":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Stop(w, owner, tankID)
+	case "Left":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Left(w, owner, tankID)
+	case "Right":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Right(w, owner, tankID)
+	case "SetMacroMoveTo":
+		tankID, x, y, _, _, _ := saveArgs(args)
+		return SetMacroMoveTo(w, owner, tankID, x, y)
+	case "SetMacroRetreat":
+		tankID, threshold, combat, _, _, _ := saveArgs(args)
+		return SetMacroRetreat(w, sc, owner, tankID, threshold, combat)
+	case "SetMacro":
+		tankID, macro, _, _, _, _ := saveArgs(args)
+		return SetMacro(w, sc, owner, tankID, macro)
+	case "Patrol":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return Patrol(w, owner, tankID, restArgs(args, 2)...)
+	case "Follow":
+		tankID, leaderID, distance, _, _, _ := saveArgs(args)
+		return Follow(w, owner, tankID, leaderID, distance)
+	case "Escort":
+		tankID, leaderID, distance, _, _, _ := saveArgs(args)
+		return Escort(w, owner, tankID, leaderID, distance)
+	case "QueueOrder":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return QueueOrder(w, sc, owner, tankID, restArgs(args, 2)...)
+	case "ClearOrders":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return ClearOrders(w, owner, tankID)
+	case "SetBehavior":
+		tankID, _, _, _, _, _ := saveArgs(args)
+		return SetBehavior(w, owner, tankID, strings.Join(restArgs(args, 2), " "))
+	case "Batch":
+		return Batch(w, sc, owner, strings.Join(restArgs(args, 1), " "), true)
+	case "DefineMacro":
+		name, _, _, _, _, _ := saveArgs(args)
+		return DefineMacro(sc, owner, name, strings.Join(restArgs(args, 2), " "))
+	case "SetStrategy":
+		tankID, strategy, _, _, _, _ := saveArgs(args)
+		return SetStrategy(w, owner, tankID, strategy)
+	default:
+		return failErr(ErrInvalidCommand)
+	}
 }
 
 // comResponse is a helper function and send messages back to the clients.
@@ -161,9 +376,42 @@ func comResponse(conn net.Conn, s string) {
 	}
 }
 
+// respond is a helper function and sends the response of a command (see ErrorCodes and RequestIDs).
+func respond(conn net.Conn, id, resp string, codes bool) {
+	if !codes {
+		resp = WithoutErrorCode(resp)
+	}
+	comResponse(conn, withRequestID(id, resp))
+}
+
+// splitRequestID is a helper function and splits a line into the request id and the command (see RequestIDs).
+func splitRequestID(line string) (id, cmd string) {
+	line = strings.TrimSpace(line)
+	if i := strings.Index(line, " "); i >= 0 {
+		return line[:i], line[i+1:]
+	}
+	return line, ""
+}
+
+// withRequestID is a helper function and adds the request id to the response (see RequestIDs).
+func withRequestID(id, resp string) string {
+	if id == "" {
+		return resp
+	}
+	return id + " " + resp
+}
+
 // saveArgs is a helper function and return 6 string arguments from the client commands
 func saveArgs(args []string) (a1, a2, a3, a4, a5, a6 string) {
 	sArgs := make([]string, 7)
 	copy(sArgs, args)
 	return sArgs[1], sArgs[2], sArgs[3], sArgs[4], sArgs[5], sArgs[6]
 }
+
+// restArgs is a helper function and return all arguments from the client commands starting at index i
+func restArgs(args []string, i int) []string {
+	if i >= len(args) {
+		return []string{}
+	}
+	return args[i:]
+}
@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+	"time"
+)
+
+// TestServer_admin runs after Test_Server_Client, because the maps create tanks with new ids.
+func TestServer_admin(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	s := NewServer(w)
+	if err := s.SetAdminToken(""); err == nil {
+		t.Error("wrong value", err)
+	}
+	_ = s.SetAdminToken("secret")
+	go func() {
+		_ = s.ListenTCP("localhost", "3341")
+	}()
+	time.Sleep(400 * time.Millisecond) // wait for server
+
+	red, _ := NewTcpClient("localhost", "3341")
+	blue, _ := NewTcpClient("localhost", "3341")
+	admin, err := NewTcpClient("localhost", "3341")
+	if err != nil || red == nil || blue == nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = red.Close()
+		_ = blue.Close()
+		_ = admin.Close()
+	}()
+
+	// roles
+	if r := Role(core.RedTank) + Role("observer-1") + Role(Guest) + Role(Admin); r != "playerobserverobserveradmin" {
+		t.Error("wrong value", r)
+	}
+	if err := red.Pause(); err == nil || err.Error() != "forbidden" {
+		t.Error("wrong value", err)
+	}
+	if resp := admin.Command("Exit"); resp != "err: forbidden" {
+		t.Error("wrong value", resp)
+	}
+	if err := red.Join(Admin, "secret"); err == nil || err.Error() != "already joined" {
+		t.Error("wrong value", err)
+	}
+	if err := admin.Join(Admin, "x"); err == nil || err.Error() != "invalid token" {
+		t.Error("wrong value", err)
+	}
+	if err := admin.Join(Admin, "secret"); err != nil {
+		t.Error("wrong value", err)
+	}
+	if me, _ := admin.MyName(); me != Admin {
+		t.Error("wrong value", me)
+	}
+
+	// pause
+	if err := admin.Pause(); err != nil || !frozen(w) {
+		t.Error("wrong value", err, frozen(w))
+	}
+	if err := admin.Resume(); err != nil || frozen(w) {
+		t.Error("wrong value", err, frozen(w))
+	}
+
+	// speed and cash
+	if err := admin.SetSpeed(0); err == nil {
+		t.Error("wrong value", err)
+	}
+	if err := admin.SetSpeed(3); err != nil || w.Speed() != 3 {
+		t.Error("wrong value", err, w.Speed())
+	}
+	if err := admin.SetCash(-1, 5); err == nil {
+		t.Error("wrong value", err)
+	}
+	if err := admin.SetCash(7, 8); err != nil {
+		t.Error("wrong value", err)
+	}
+	if r, b := w.CashStat(); r != 7 || b != 8 {
+		t.Error("wrong value", r, b)
+	}
+
+	// maps
+	if err := admin.Restart(); err == nil || err.Error() != "no map loaded" {
+		t.Error("wrong value", err)
+	}
+	if err := admin.LoadMap("x"); err == nil || err.(Error).Name != NameMapNotFound {
+		t.Error("wrong value", err)
+	}
+	if err := admin.LoadMap("test"); err != nil || len(w.Tanks()) == 0 {
+		t.Error("wrong value", err, len(w.Tanks()))
+	}
+	_ = admin.SetCash(7, 8)
+	if err := admin.Restart(); err != nil {
+		t.Error("wrong value", err)
+	}
+	if r, _ := w.CashStat(); r == 7 {
+		t.Error("wrong value", r)
+	}
+	if me, _ := red.MyName(); me != core.RedTank {
+		t.Error("wrong value", me)
+	}
+}
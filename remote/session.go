@@ -0,0 +1,196 @@
+package remote
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"io"
+	"time"
+)
+
+// session is the player slot of a connection that can be reclaimed after a disconnect (see command Reconnect).
+type session struct {
+	token     string      // session token (see welcome line)
+	conn      io.Closer   // connection of the player (nil while disconnected)
+	timer     *time.Timer // forfeit timer while disconnected (see SetDisconnectPolicy)
+	forfeited bool        // the player didn't reconnect in time
+	wins      int         // won games of the series (see SetSeries)
+}
+
+// newSessionToken returns a new random session token.
+func newSessionToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SetDisconnectPolicy sets what happens while a player of a running game is disconnected.
+// With pause, the world is frozen until all players are connected again.
+// A player that doesn't reconnect within the forfeit timeout loses all units (0 disables the timeout).
+func (s *Server) SetDisconnectPolicy(pause bool, forfeit time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.pause, s.forfeit = pause, forfeit
+}
+
+// SetCountdown sets the countdown before the game starts (see core.StateCountdown).
+// The countdown is also used after a new map is loaded (see LoadMap).
+func (s *Server) SetCountdown(d time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.countdown = d
+}
+
+// countdownTicks returns the countdown in iterations (call with lock).
+func (s *Server) countdownTicks() uint64 {
+	return uint64(s.countdown.Seconds() * core.GameSpeed)
+}
+
+// start starts the game with both players (call with lock).
+func (s *Server) start() {
+	s.started = true
+	fmt.Printf("START GAME\n")
+	s.world.Lock()
+	s.world.Start(s.countdownTicks())
+	s.world.Unlock()
+	s.freeze()
+}
+
+// freeze freezes the world unless the game is running (call with lock, but without the world lock):
+// both players have joined, no admin has paused the game (see Pause) and no player is disconnected
+// (see SetDisconnectPolicy).
+func (s *Server) freeze() {
+	running := s.started && !s.paused && (!s.pause || s.connected())
+	s.world.Lock()
+	s.world.Freeze(!running)
+	s.world.Unlock()
+}
+
+// claim connects the player slot with the connection and returns the session token (call with lock).
+// An old connection of the player is closed (see reconnect).
+func (s *Server) claim(player string, conn io.Closer) string {
+	sess := s.sessions[player]
+	if sess == nil {
+		sess = &session{token: newSessionToken()}
+		s.sessions[player] = sess
+	}
+	if sess.timer != nil {
+		sess.timer.Stop()
+		sess.timer = nil
+	}
+	if sess.conn != nil && sess.conn != conn {
+		_ = sess.conn.Close() // replaced
+	}
+	sess.conn = conn
+
+	// resume the paused game
+	if s.started {
+		s.freeze()
+	}
+	return sess.token
+}
+
+// connected returns true if both players are connected or have forfeited (call with lock).
+func (s *Server) connected() bool {
+	for _, player := range []string{core.RedTank, core.BlueTank} {
+		if sess := s.sessions[player]; sess == nil || (sess.conn == nil && !sess.forfeited) {
+			return false
+		}
+	}
+	return true
+}
+
+// reconnect is the command Reconnect: the connection reclaims the player slot of the session token.
+// It returns the new player of the connection and the response.
+func (s *Server) reconnect(owner, token string, conn io.Closer) (string, string) {
+	if owner == core.RedTank || owner == core.BlueTank {
+		return owner, fail(CodeConflict, NameAlreadyJoined, "already joined")
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for player, sess := range s.sessions {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(sess.token)) != 1 {
+			continue
+		}
+		if sess.forfeited {
+			return owner, fail(CodeConflict, NameForfeited, "forfeited")
+		}
+		s.claim(player, conn)
+		fmt.Printf("%s has reconnected as %s\n", owner, player)
+		return player, "ok"
+	}
+	return owner, fail(CodeUnauthorized, NameInvalidSession, "invalid session")
+}
+
+// leave disconnects the player slot of a closed connection.
+// The slot can be reclaimed with the session token (see SetDisconnectPolicy).
+func (s *Server) leave(owner string, conn io.Closer) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	owner = s.seat(owner, conn) // see SetSeries
+	fmt.Printf("player %s has left\n", owner)
+	sess := s.sessions[owner]
+	if sess == nil || sess.conn != conn {
+		return // observer or replaced connection
+	}
+	sess.conn = nil
+	if !s.started {
+		return // before the start, only the session is disconnected (the slot stays taken)
+	}
+
+	// pause
+	s.freeze()
+
+	// forfeit (not after the end, because Clear would erase the units of the loser)
+	if s.forfeit > 0 && !s.ended() {
+		sess.timer = time.AfterFunc(s.forfeit, func() {
+			s.forfeited(sess)
+		})
+	}
+}
+
+// ended returns true if the current game is finished or the series is decided (call with lock).
+func (s *Server) ended() bool {
+	s.world.Lock()
+	defer s.world.Unlock()
+
+	return s.decided || s.world.State() == core.StateFinished
+}
+
+// forfeited removes all units of a player that didn't reconnect in time (see SetDisconnectPolicy).
+// The other player wins the game (see core.ReasonForfeit).
+func (s *Server) forfeited(sess *session) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if sess.conn != nil || sess.forfeited {
+		return // reconnected
+	}
+	if s.ended() {
+		return // the game has ended in the meantime
+	}
+	sess.forfeited = true
+
+	player := core.RedTank // the sides can be swapped (see SetSeries)
+	if s.sessions[core.BlueTank] == sess {
+		player = core.BlueTank
+	}
+	winner := core.RedTank
+	if player == core.RedTank {
+		winner = core.BlueTank
+	}
+	s.world.Lock()
+	s.world.Finish(winner, core.ReasonForfeit)
+	s.world.Clear(player)
+	s.update(s.world) // the world is no longer updated
+	s.world.Unlock()
+	fmt.Printf("player %s has forfeited\n", player)
+	s.freeze()
+}
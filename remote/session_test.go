@@ -0,0 +1,137 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+	"time"
+)
+
+func TestServer_Reconnect(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	tank, _ := core.NewTank(w, core.BlueTank, 5, 40, core.WeaponCannon)
+	tank.SetPosition(core.NewPosition(100, 100), core.East)
+	w.AddTank(tank)
+	tank, _ = core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	tank.SetPosition(core.NewPosition(20, 100), core.West)
+	w.AddTank(tank)
+	s := NewServer(w)
+	s.SetDisconnectPolicy(true, 0)
+	s.SetCountdown(time.Second)
+	go func() {
+		_ = s.ListenTCP("localhost", "3340")
+	}()
+	time.Sleep(400 * time.Millisecond) // wait for server
+
+	red, _ := NewTcpClient("localhost", "3340")
+	blue, err := NewTcpClient("localhost", "3340")
+	if err != nil || red == nil {
+		t.Fatal(err)
+	}
+	session := red.Session()
+	if len(session) != 32 || blue.Session() == "" || blue.Session() == session {
+		t.Error("wrong value", session, blue.Session())
+	}
+	if gs, _ := red.GameStatus(); gs.State != core.StateCountdown || gs.Countdown != core.GameSpeed {
+		t.Error("wrong value", gs.State, gs.Countdown)
+	}
+
+	// pause
+	_ = red.Close()
+	time.Sleep(50 * time.Millisecond)
+	if !frozen(w) {
+		t.Error("game not paused")
+	}
+
+	// reclaim
+	obs, _ := NewTcpClient("localhost", "3340")
+	if me, _ := obs.MyName(); me != "observer-1" || obs.Session() != "" {
+		t.Error("wrong value", me, obs.Session())
+	}
+	if err := obs.Reconnect("x"); err == nil || err.Error() != "invalid session" {
+		t.Error("wrong value", err)
+	}
+	if err := obs.Reconnect(session); err != nil {
+		t.Error("wrong value", err)
+	}
+	if me, _ := obs.MyName(); me != core.RedTank || frozen(w) {
+		t.Error("wrong value", me, frozen(w))
+	}
+	if err := obs.Reconnect(session); err == nil || err.Error() != "already joined" {
+		t.Error("wrong value", err)
+	}
+
+	// the client reclaims the slot after a reconnect
+	red, _ = NewTcpClient("localhost", "3340")
+	red.SetHooks(Hooks{Reconnect: true})
+	if err := red.Reconnect(session); err != nil {
+		t.Error("wrong value", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if me, _ := obs.MyName(); me == core.RedTank {
+		t.Error("old connection not closed")
+	}
+	_ = obs.Close()
+	thief, _ := NewTcpClient("localhost", "3340")
+	if err := thief.Reconnect(session); err != nil {
+		t.Error("wrong value", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if me, _ := red.MyName(); me != core.RedTank {
+		t.Error("wrong value", me)
+	}
+	_ = thief.Close()
+
+	// forfeit
+	s.SetDisconnectPolicy(false, 100*time.Millisecond)
+	session = blue.Session()
+	_ = blue.Close()
+	time.Sleep(50 * time.Millisecond)
+	if _, n := unitCount(w); n != 1 || frozen(w) {
+		t.Error("wrong value", n, frozen(w))
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, n := unitCount(w); n != 0 {
+		t.Error("wrong value", n)
+	}
+	if r, err := red.GameResult(); err != nil || r.State != core.StateFinished || r.Winner != core.RedTank || r.Reason != core.ReasonForfeit {
+		t.Error("wrong value", r, err)
+	}
+	blue, _ = NewTcpClient("localhost", "3340")
+	if err := blue.Reconnect(session); err == nil || err.Error() != "forfeited" {
+		t.Error("wrong value", err)
+	}
+	_ = blue.Close()
+
+	// no forfeit after the end of the game
+	n, _ := unitCount(w)
+	_ = red.Close()
+	time.Sleep(200 * time.Millisecond)
+	if r, _ := unitCount(w); r != n || n == 0 {
+		t.Error("wrong value", r, n)
+	}
+	w.Lock()
+	winner, _ := w.Result()
+	w.Unlock()
+	if winner != core.RedTank {
+		t.Error("wrong value", winner)
+	}
+}
+
+// frozen returns the freeze state of the world with lock, because the server changes it concurrently.
+func frozen(w *core.World) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.IsFrozen()
+}
+
+// unitCount returns the units of red and blue with lock (see frozen).
+func unitCount(w *core.World) (int, int) {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.UnitCount()
+}
@@ -0,0 +1,70 @@
+package remote
+
+import (
+	_ "embed" // spectator page
+	"net/http"
+	"strconv"
+)
+
+//go:embed web/spectator.html
+var spectatorPage []byte // renders the world in the browser (see serveSpectatorPage)
+
+// serveSpectatorPage serves the spectator page that shows the game in the browser.
+func (s *Server) serveSpectatorPage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(spectatorPage)
+}
+
+// serveSpectator streams the world (see GameStatus) over WebSocket after every n iterations (?every=n; default 1).
+// Spectators don't join the game and don't use a player slot.
+func (s *Server) serveSpectator(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.ParseUint(r.URL.Query().Get("every"), 10, 64)
+	if err != nil || n == 0 {
+		n = 1
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	// first world (the later worlds are pushed by the hub with lock, see update)
+	s.world.Lock()
+	jw := NewJsonWorld(s.world)
+	s.world.Unlock()
+	if ws.WriteMessage(jw.Get()) != nil {
+		return
+	}
+
+	// wait for close
+	done := make(chan struct{})
+	go func() {
+		for {
+			if _, err := ws.ReadMessage(); err != nil {
+				close(done)
+				return
+			}
+		}
+	}()
+
+	// push
+	ch := s.hub.subscribe(n)
+	defer s.hub.unsubscribe(ch)
+	for {
+		select {
+		case line := <-ch:
+			if ws.WriteMessage(line) != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_serveSpectatorPage(t *testing.T) {
+	srv := httptest.NewServer(NewServer(core.NewWorld(100, 200)).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(b), "/spectate?every=") {
+		t.Error("wrong value", resp.StatusCode)
+	}
+
+	if resp, err := http.Get(srv.URL + "/unknown"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Error("wrong value", resp, err)
+	}
+}
+
+func TestServer_serveSpectator(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	s := NewServer(w)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	// connect
+	c := spectate(t, srv, 2)
+	defer c.Close()
+
+	// first world
+	jw := new(JsonWorld)
+	jw.Set(c.read(t))
+	if jw.Iteration != 0 || !jw.Freeze {
+		t.Error("wrong value", jw.Iteration, jw.Freeze)
+	}
+
+	// stream
+	time.Sleep(50 * time.Millisecond) // wait for subscription
+	w.Freeze(false)
+	w.UpdateN(4)
+	for _, want := range []uint64{2, 4} {
+		if err := json.Unmarshal([]byte(c.read(t)), jw); err != nil || jw.Iteration != want {
+			t.Error("wrong value", jw.Iteration, err)
+		}
+	}
+
+	// no player slot
+	if owner, _ := s.join(c.LocalAddr(), nil); owner != core.RedTank {
+		t.Error("wrong value", owner)
+	}
+}
+
+func TestServer_serveSpectator_Update(t *testing.T) {
+	resources.MuteSound = true
+
+	w := core.NewWorld(100, 200)
+	tank, _ := core.NewTank(w, core.RedTank, 5, 40, core.WeaponCannon)
+	tank.SetPosition(core.NewPosition(50, 100), core.East)
+	w.AddTank(tank)
+	tank, _ = core.NewTank(w, core.BlueTank, 5, 40, core.WeaponCannon)
+	tank.SetPosition(core.NewPosition(50, 150), core.West)
+	w.AddTank(tank)
+	srv := httptest.NewServer(NewServer(w).Handler())
+	w.Freeze(false) // start without players
+	defer srv.Close()
+
+	// the first world is read while the world is updated (run with -race)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				w.Update()
+			}
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		c := spectate(t, srv, 1000)
+		jw := new(JsonWorld)
+		jw.Set(c.read(t))
+		if len(jw.Tanks) != 2 || jw.State != core.StateRunning {
+			t.Error("wrong value", jw.Tanks, jw.State)
+		}
+		_ = c.Close()
+	}
+}
+
+// spectate is a helper function and opens a spectator stream (see serveSpectator).
+func spectate(t *testing.T, srv *httptest.Server, every int) *testWebSocket {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(conn, "GET /spectate?every="+strconv.Itoa(every)+" HTTP/1.1\r\nHost: test\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	if resp, err := http.ReadResponse(r, nil); err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatal("handshake", resp, err)
+	}
+	return &testWebSocket{Conn: conn, r: r}
+}
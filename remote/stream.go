@@ -0,0 +1,128 @@
+package remote
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"net"
+	"net/textproto"
+	"sync"
+)
+
+// streamBuffer is the number of world updates that are buffered per subscriber.
+// Slow subscribers skip updates.
+const streamBuffer = 8
+
+// hub pushes the world to all subscribers after every update (see command Subscribe).
+// It also pushes the new game events (see command SubscribeEvents)
+// and keeps the served world states of all connections (see command GameStatusSince).
+type hub struct {
+	mux      sync.Mutex
+	subs     map[chan string]uint64 // subscriber -> every n ticks
+	events   map[chan string]bool   // event subscribers
+	eventSeq uint64                 // last pushed event
+	history  history
+}
+
+// newHub returns a new hub for the world (see update).
+func newHub(w *core.World) *hub {
+	return &hub{
+		subs:     make(map[chan string]uint64),
+		events:   make(map[chan string]bool),
+		eventSeq: w.EventSeq(),
+	}
+}
+
+// subscribe adds a subscriber that receives the world after every n iterations.
+func (h *hub) subscribe(n uint64) chan string {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	ch := make(chan string, streamBuffer)
+	h.subs[ch] = n
+	return ch
+}
+
+// subscribeEvents adds a subscriber that receives the new game events after every update.
+func (h *hub) subscribeEvents() chan string {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	ch := make(chan string, streamBuffer)
+	h.events[ch] = true
+	return ch
+}
+
+// unsubscribe removes the subscriber.
+func (h *hub) unsubscribe(ch chan string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	delete(h.subs, ch)
+	delete(h.events, ch)
+}
+
+// update sends the world to all subscribers (without blocking the world).
+// It is called after every update of the world (see Server.update).
+func (h *hub) update(w *core.World) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	var line string // serialized once
+	for ch, n := range h.subs {
+		if w.Iteration()%n != 0 {
+			continue // not this tick
+		}
+		if line == "" {
+			jw := NewJsonWorld(w)
+			line = jw.Get()
+		}
+		send(ch, line)
+	}
+
+	// events
+	if w.EventSeq() == h.eventSeq {
+		return // no new events
+	}
+	es := NewJsonEvents(w.Events(h.eventSeq))
+	h.eventSeq = w.EventSeq()
+	line = es.Get()
+	for ch := range h.events {
+		send(ch, line)
+	}
+}
+
+// send pushes the line to the subscriber without blocking.
+func send(ch chan string, line string) {
+	select {
+	case ch <- line:
+	default: // subscriber is too slow
+	}
+}
+
+// stream pushes the lines of the subscription to the connection until it is closed (BLOCKING!).
+// All lines of the client are ignored.
+func stream(conn net.Conn, tp *textproto.Reader, h *hub, ch chan string) {
+	defer h.unsubscribe(ch)
+
+	// wait for close
+	done := make(chan struct{})
+	go func() {
+		for {
+			if _, err := tp.ReadLine(); err != nil {
+				close(done)
+				return
+			}
+		}
+	}()
+
+	// push
+	for {
+		select {
+		case line := <-ch:
+			if _, err := conn.Write([]byte(line + "\r\n")); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
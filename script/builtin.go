@@ -0,0 +1,128 @@
+package script
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/macro"
+)
+
+// builtin is a function that can be called by scripts.
+type builtin struct {
+	arity int
+	f     func(t *core.Tank, args []int) int
+}
+
+// builtins are all functions that can be called by scripts.
+// The functions are bound to the core functions of the tank and the macros.
+var builtins = map[string]builtin{
+	// tank
+	"health":  {0, func(t *core.Tank, _ []int) int { return t.Health() }},
+	"armor":   {0, func(t *core.Tank, _ []int) int { return t.Armor() }},
+	"speed":   {0, func(t *core.Tank, _ []int) int { return t.Speed() }},
+	"x":       {0, func(t *core.Tank, _ []int) int { return t.Pos().X }},
+	"y":       {0, func(t *core.Tank, _ []int) int { return t.Pos().Y }},
+	"angle":   {0, func(t *core.Tank, _ []int) int { return t.Angle() }},
+	"moving":  {0, func(t *core.Tank, _ []int) int { return bool2int(t.Moving()) }},
+	"blocked": {0, func(t *core.Tank, _ []int) int { return bool2int(t.Blocked()) }},
+	"ready": {0, func(t *core.Tank, _ []int) int {
+		rdy, _ := t.Status()
+		return bool2int(rdy)
+	}},
+	"range": {0, func(t *core.Tank, _ []int) int {
+		if t.Weapon() == nil {
+			return 0
+		}
+		return t.Weapon().Range()
+	}},
+
+	// world
+	"iteration": {0, func(t *core.Tank, _ []int) int {
+		if t.World() == nil {
+			return 0
+		}
+		return int(t.World().Iteration())
+	}},
+	"rand": {1, func(t *core.Tank, args []int) int {
+		if args[0] <= 0 {
+			return 0
+		}
+		return t.World().Random(args[0])
+	}},
+
+	// targets
+	"targets": {0, func(t *core.Tank, _ []int) int { return len(core.PossibleTargets(t, macro.Filters(t)...)) }},
+	"targetx": {0, func(t *core.Tank, _ []int) int { return target(t, func(ot core.Target) int { return ot.Tank.Pos().X }) }},
+	"targety": {0, func(t *core.Tank, _ []int) int { return target(t, func(ot core.Target) int { return ot.Tank.Pos().Y }) }},
+	"targetdist": {0, func(t *core.Tank, _ []int) int {
+		return target(t, func(ot core.Target) int { return ot.Distance })
+	}},
+	"targethealth": {0, func(t *core.Tank, _ []int) int {
+		return target(t, func(ot core.Target) int { return ot.Tank.Health() })
+	}},
+
+	// actions
+	"forward":  {0, func(t *core.Tank, _ []int) int { t.Forward(); return 1 }},
+	"backward": {0, func(t *core.Tank, _ []int) int { t.Backward(); return 1 }},
+	"stop":     {0, func(t *core.Tank, _ []int) int { t.Stop(); return 1 }},
+	"left":     {0, func(t *core.Tank, _ []int) int { t.Left(); return 1 }},
+	"right":    {0, func(t *core.Tank, _ []int) int { t.Right(); return 1 }},
+	"fire": {2, func(t *core.Tank, args []int) int {
+		ok, _ := t.Fire(args[0], args[1])
+		return bool2int(ok)
+	}},
+	"fireat": {2, func(t *core.Tank, args []int) int {
+		ok, _ := t.FireAt(core.NewPosition(args[0], args[1]))
+		return bool2int(ok)
+	}},
+	"fireattarget": {0, func(t *core.Tank, _ []int) int {
+		ot, ok := macro.SelectTarget(t, core.PossibleTargets(t, macro.Filters(t)...))
+		if ok {
+			macro.Attack(t, ot)
+		}
+		return bool2int(ok)
+	}},
+
+	// macros: 0 is running, 1 is done, -1 is failed
+	"moveto": {2, func(t *core.Tank, args []int) int {
+		return orderResult(macro.MoveTo(t, core.NewPosition(args[0], args[1])))
+	}},
+	"guard": {0, func(t *core.Tank, _ []int) int {
+		return orderResult(macro.GuardMode(t, macro.Filters(t)...))
+	}},
+	"attackmove": {0, func(t *core.Tank, _ []int) int { macro.AttackMove(t, macro.Filters(t)...); return 0 }},
+	"kite":       {0, func(t *core.Tank, _ []int) int { macro.Kite(t, macro.Filters(t)...); return 0 }},
+}
+
+// scanning are the builtins that search all tanks of the world (see core.PossibleTargets).
+// They cost one instruction per tank (see MaxSteps).
+var scanning = map[string]bool{
+	"targets":      true,
+	"targetx":      true,
+	"targety":      true,
+	"targetdist":   true,
+	"targethealth": true,
+	"fireattarget": true,
+	"guard":        true,
+	"attackmove":   true,
+	"kite":         true,
+}
+
+// target returns a value of the target selected by the strategy of the tank or -1 (see macro.SelectTarget).
+func target(t *core.Tank, value func(ot core.Target) int) int {
+	ot, ok := macro.SelectTarget(t, core.PossibleTargets(t, macro.Filters(t)...))
+	if !ok {
+		return -1
+	}
+	return value(ot)
+}
+
+// orderResult converts the result of a macro: 0 is running, 1 is done, -1 is failed.
+func orderResult(s core.OrderState) int {
+	switch s {
+	case core.OrderDone:
+		return 1
+	case core.OrderFailed:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,10 @@
+// Package script is a tiny, sandboxed scripting language for user-defined macros.
+// Scripts are interpreted with every update and limited by a strict instruction budget per tick (see MaxSteps).
+//
+//	hp = health();
+//	if hp < 30 { moveto(100, 200); return; }
+//	if targets() > 0 { fireattarget(); } else { attackmove(); }
+//
+// All values are integers (true is 1, false is 0). Variables keep their values between the ticks;
+// undefined variables are 0.
+package script
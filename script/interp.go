@@ -0,0 +1,265 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SchnorcherSepp/TankWars/core"
+)
+
+// limits of the interpreter
+const (
+	MaxSteps = 1000 // max. instructions per tick
+	MaxVars  = 64   // max. number of variables
+)
+
+// errors of the interpreter
+var (
+	ErrBudget = fmt.Errorf("instruction limit exceeded (max. %d per tick)", MaxSteps)
+	errReturn = errors.New("return") // ends the tick
+)
+
+// Program is a compiled script.
+// It can be attached to many tanks (see NewMachine).
+type Program struct {
+	Name string
+	body []stmt
+}
+
+// Compile parses the source code.
+func Compile(name, src string) (*Program, error) {
+	body, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{Name: name, body: body}, nil
+}
+
+// Machine runs a program for one tank.
+// The variables keep their values between the ticks.
+type Machine struct {
+	prog  *Program
+	vars  map[string]int
+	steps int
+	err   error
+}
+
+// NewMachine returns a new machine for the program.
+func NewMachine(p *Program) *Machine {
+	return &Machine{
+		prog: p,
+		vars: make(map[string]int),
+	}
+}
+
+// Err returns the error of the last tick or nil.
+func (m *Machine) Err() error {
+	return m.err
+}
+
+// Var returns the value of a variable.
+func (m *Machine) Var(name string) (int, bool) {
+	v, ok := m.vars[name]
+	return v, ok
+}
+
+// Update is the macro function (see core.Tank.SetMacro).
+// Errors stop the current tick; they are reported with core.PhaseError (see Err).
+func (m *Machine) Update(t *core.Tank) {
+	if m == nil || t == nil {
+		return // EXIT
+	}
+	if m.Run(t) != nil {
+		t.SetMacroState(core.MacroState{Phase: core.PhaseError})
+	}
+}
+
+// Run executes the program once with a fresh instruction budget.
+func (m *Machine) Run(t *core.Tank) error {
+	m.steps = 0
+	m.err = m.block(t, m.prog.body)
+	if m.err == errReturn {
+		m.err = nil
+	}
+	return m.err
+}
+
+// step counts one instruction.
+func (m *Machine) step() error {
+	return m.stepN(1)
+}
+
+// stepN counts n instructions.
+func (m *Machine) stepN(n int) error {
+	m.steps += n
+	if m.steps > MaxSteps {
+		return ErrBudget
+	}
+	return nil
+}
+
+// block executes a list of statements.
+func (m *Machine) block(t *core.Tank, list []stmt) error {
+	for _, s := range list {
+		if err := m.statement(t, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statement executes one statement.
+func (m *Machine) statement(t *core.Tank, s stmt) error {
+	if err := m.step(); err != nil {
+		return err
+	}
+
+	switch s := s.(type) {
+	case *assignStmt:
+		v, err := m.eval(t, s.value)
+		if err != nil {
+			return err
+		}
+		if _, ok := m.vars[s.name]; !ok && len(m.vars) >= MaxVars {
+			return fmt.Errorf("too many variables (max. %d)", MaxVars)
+		}
+		m.vars[s.name] = v
+
+	case *exprStmt:
+		_, err := m.eval(t, s.value)
+		return err
+
+	case *ifStmt:
+		c, err := m.eval(t, s.cond)
+		if err != nil {
+			return err
+		}
+		if c != 0 {
+			return m.block(t, s.then)
+		}
+		return m.block(t, s.els)
+
+	case *whileStmt:
+		for {
+			c, err := m.eval(t, s.cond)
+			if err != nil {
+				return err
+			}
+			if c == 0 {
+				return nil
+			}
+			if err := m.block(t, s.body); err != nil {
+				return err
+			}
+			if err := m.step(); err != nil {
+				return err
+			}
+		}
+
+	case *returnStmt:
+		return errReturn
+	}
+	return nil
+}
+
+// eval calculates the value of an expression.
+func (m *Machine) eval(t *core.Tank, e expr) (int, error) {
+	if err := m.step(); err != nil {
+		return 0, err
+	}
+
+	switch e := e.(type) {
+	case *intExpr:
+		return e.value, nil
+
+	case *varExpr:
+		return m.vars[e.name], nil // undefined variables are 0
+
+	case *callExpr:
+		args := make([]int, len(e.args))
+		for i, a := range e.args {
+			v, err := m.eval(t, a)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = v
+		}
+		if scanning[e.name] && t.World() != nil {
+			if err := m.stepN(len(t.World().Tanks())); err != nil {
+				return 0, err
+			}
+		}
+		return builtins[e.name].f(t, args), nil
+
+	case *unaryExpr:
+		x, err := m.eval(t, e.x)
+		if err != nil {
+			return 0, err
+		}
+		if e.op == "-" {
+			return -x, nil
+		}
+		return bool2int(x == 0), nil
+
+	case *binaryExpr:
+		x, err := m.eval(t, e.x)
+		if err != nil {
+			return 0, err
+		}
+		// short-circuit
+		if e.op == "&&" && x == 0 {
+			return 0, nil
+		}
+		if e.op == "||" && x != 0 {
+			return 1, nil
+		}
+		y, err := m.eval(t, e.y)
+		if err != nil {
+			return 0, err
+		}
+		return binary(e.op, x, y)
+	}
+	return 0, errors.New("invalid expression")
+}
+
+// binary calculates x op y.
+func binary(op string, x, y int) (int, error) {
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/", "%":
+		if y == 0 {
+			return 0, errors.New("division by zero")
+		}
+		if op == "/" {
+			return x / y, nil
+		}
+		return x % y, nil
+	case "==":
+		return bool2int(x == y), nil
+	case "!=":
+		return bool2int(x != y), nil
+	case "<":
+		return bool2int(x < y), nil
+	case "<=":
+		return bool2int(x <= y), nil
+	case ">":
+		return bool2int(x > y), nil
+	case ">=":
+		return bool2int(x >= y), nil
+	case "&&", "||":
+		return bool2int(y != 0), nil // x is checked by eval
+	}
+	return 0, fmt.Errorf("invalid operator '%s'", op)
+}
+
+// bool2int converts true to 1 and false to 0.
+func bool2int(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
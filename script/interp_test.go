@@ -0,0 +1,172 @@
+package script
+
+import (
+	"github.com/SchnorcherSepp/TankWars/core"
+	"github.com/SchnorcherSepp/TankWars/gui/resources"
+	"testing"
+)
+
+func TestMachine(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(red)
+
+	p, err := Compile("test", `
+		if n == 0 { n = 10; }
+		n = n + 1;
+		i = 0; sum = 0;
+		while i < 5 { i = i + 1; sum = sum + i; }
+		neg = -7 / 2 + 8 % 3;
+		px = x();
+		if health() > 0 || 1 / 0 { forward(); }
+		if 0 && 1 / 0 { stop(); }
+		return;
+		n = 0;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// run twice
+	m := NewMachine(p)
+	for i := 0; i < 2; i++ {
+		m.Update(red)
+	}
+	if m.Err() != nil || red.MacroState().Phase == core.PhaseError {
+		t.Error("wrong value", m.Err())
+	}
+	for name, want := range map[string]int{"n": 12, "i": 5, "sum": 15, "neg": -1, "px": 300} {
+		if v, ok := m.Var(name); !ok || v != want {
+			t.Error("wrong value", name, v, ok)
+		}
+	}
+	if !red.Moving() {
+		t.Error("tank should move forward")
+	}
+}
+
+func TestMachine_Errors(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	w.AddTank(red)
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{`while 1 { a = a + 1; }`, ErrBudget.Error()},
+		{`a = 1 / 0;`, "division by zero"},
+		{`a = 5 % (3 - 3);`, "division by zero"},
+	}
+	for _, tt := range tests {
+		p, err := Compile("test", tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewMachine(p)
+		m.Update(red)
+		if m.Err() == nil || m.Err().Error() != tt.err || red.MacroState().Phase != core.PhaseError {
+			t.Error("wrong value", tt.src, m.Err())
+		}
+	}
+
+	// too many variables
+	src := ""
+	for i := 0; i <= MaxVars; i++ {
+		src += "v" + string(rune('a'+i%26)) + string(rune('a'+i/26)) + " = 1;"
+	}
+	p, err := Compile("test", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewMachine(p).Run(red); err == nil || err.Error() != "too many variables (max. 64)" {
+		t.Error("wrong value", err)
+	}
+}
+
+func TestMachine_scanCost(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world with 100 tanks
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	w.AddTank(red)
+	for i := 1; i < 100; i++ {
+		rock, _ := core.NewTank(w, core.NeutralRock, 5, 25, core.WeaponNone)
+		w.AddTank(rock)
+	}
+
+	// every search costs one instruction per tank
+	p, _ := Compile("test", `while 1 { n = n + 1; a = targets(); }`)
+	m := NewMachine(p)
+	if err := m.Run(red); err != ErrBudget {
+		t.Error("wrong value", err)
+	}
+	if n, _ := m.Var("n"); n < 5 || n > 10 {
+		t.Error("wrong value", n)
+	}
+}
+
+func TestMachine_Builtins(t *testing.T) {
+	resources.MuteSound = true // mute sound for tests
+
+	// prepare world
+	w := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	red, _ := core.NewTank(w, core.RedTank, 5, 25, core.WeaponRockets)
+	red.SetPosition(core.NewPosition(300, 300), core.East)
+	w.AddTank(red)
+	blue, _ := core.NewTank(w, core.BlueTank, 5, 25, core.WeaponRockets)
+	blue.SetPosition(core.NewPosition(400, 300), core.West)
+	w.AddTank(blue)
+	w.UpdateN(int(red.Weapon().ReloadTime() + red.Weapon().PreparationTime()))
+
+	p, err := Compile("test", `
+		t = targets(); tx = targetx(); ty = targety(); td = targetdist(); th = targethealth();
+		r = rand(10); r0 = rand(0); rdy = ready(); rng = range(); a = angle();
+		done = moveto(300, 300);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMachine(p)
+	if err := m.Run(red); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"t": 1, "tx": 400, "ty": 300, "td": 100, "th": blue.Health(),
+		"r0": 0, "rdy": 1, "rng": red.Weapon().Range(), "a": core.East, "done": 1} {
+		if v, _ := m.Var(name); v != want {
+			t.Error("wrong value", name, v, want)
+		}
+	}
+	if r, _ := m.Var("r"); r < 0 || r >= 10 {
+		t.Error("wrong value", r)
+	}
+
+	// fire at the target
+	p, _ = Compile("test", `ok = fireattarget();`)
+	m = NewMachine(p)
+	if err := m.Run(red); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.Var("ok"); v != 1 || len(w.Projectiles()) != 1 {
+		t.Error("wrong value", v, len(w.Projectiles()))
+	}
+
+	// no target
+	w2 := core.NewWorld(core.WorldXWidth, core.WorldYHeight)
+	solo, _ := core.NewTank(w2, core.RedTank, 5, 25, core.WeaponRockets)
+	w2.AddTank(solo)
+	p, _ = Compile("test", `tx = targetx();`)
+	m = NewMachine(p)
+	_ = m.Run(solo)
+	if v, _ := m.Var("tx"); v != -1 {
+		t.Error("wrong value", v)
+	}
+}
@@ -0,0 +1,102 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// token types
+const (
+	tokEOF   = iota // end of input
+	tokInt          // integer literal
+	tokIdent        // name of a variable, function or keyword
+	tokOp           // operator or punctuation
+)
+
+// token is a part of the source code.
+type token struct {
+	typ int
+	val string
+	num int
+	pos int // offset in the source code
+}
+
+// lex splits the source code into tokens.
+// Comments start with '#' and end at the end of the line.
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0, len(src)/2)
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++ // whitespace
+
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++ // comment
+			}
+
+		case isDigit(c):
+			start := i
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			n, err := strconv.Atoi(src[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("pos %d: invalid number", start)
+			}
+			tokens = append(tokens, token{typ: tokInt, val: src[start:i], num: n, pos: start})
+
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokIdent, val: src[start:i], pos: start})
+
+		default:
+			// two char operators
+			if i+1 < len(src) {
+				switch op := src[i : i+2]; op {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, token{typ: tokOp, val: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			// one char operators
+			switch c {
+			case '+', '-', '*', '/', '%', '<', '>', '!', '=', '(', ')', '{', '}', ',', ';':
+				tokens = append(tokens, token{typ: tokOp, val: string(c), pos: i})
+				i++
+			default:
+				return nil, fmt.Errorf("pos %d: unexpected character '%c'", i, c)
+			}
+		}
+	}
+
+	return append(tokens, token{typ: tokEOF, pos: len(src)}), nil
+}
+
+// isDigit returns true for 0-9.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isLetter returns true for a-z, A-Z and '_'.
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+// OneLine removes all comments and line breaks from the source code (see DefineMacro in the line based protocol).
+func OneLine(src string) string {
+	lines := strings.Split(src, "\n")
+	for i, l := range lines {
+		if c := strings.IndexByte(l, '#'); c >= 0 {
+			lines[i] = l[:c]
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+}
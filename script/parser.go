@@ -0,0 +1,374 @@
+package script
+
+import (
+	"fmt"
+)
+
+// limits of the parser
+const (
+	MaxLength  = 4096 // max. length of the source code
+	MaxNesting = 32   // max. nesting of blocks and expressions
+)
+
+//---------------- AST -----------------------------------------------------------------------------------------------//
+
+// stmt is a statement of the AST.
+type stmt interface{}
+
+// expr is an expression of the AST.
+type expr interface{}
+
+type (
+	assignStmt struct { // name = value;
+		name  string
+		value expr
+	}
+	exprStmt struct { // value;
+		value expr
+	}
+	ifStmt struct { // if cond { then } else { els }
+		cond expr
+		then []stmt
+		els  []stmt
+	}
+	whileStmt struct { // while cond { body }
+		cond expr
+		body []stmt
+	}
+	returnStmt struct{} // return;
+
+	intExpr struct { // 123
+		value int
+	}
+	varExpr struct { // name
+		name string
+	}
+	callExpr struct { // name(args)
+		name string
+		args []expr
+	}
+	unaryExpr struct { // -x or !x
+		op string
+		x  expr
+	}
+	binaryExpr struct { // x op y
+		op   string
+		x, y expr
+	}
+)
+
+//---------------- PARSER --------------------------------------------------------------------------------------------//
+
+// parser is a recursive descent parser.
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// parse converts the source code into an AST.
+func parse(src string) ([]stmt, error) {
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("script too long (max. %d)", MaxLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	list := make([]stmt, 0, 8)
+	for p.peek().typ != tokEOF {
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and moves on.
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is returns true if the current token is the given operator or keyword.
+func (p *parser) is(val string) bool {
+	t := p.peek()
+	return (t.typ == tokOp || t.typ == tokIdent) && t.val == val
+}
+
+// expect consumes the given operator or returns an error.
+func (p *parser) expect(val string) error {
+	if !p.is(val) {
+		return p.errorf("expected '%s'", val)
+	}
+	p.next()
+	return nil
+}
+
+// errorf returns an error with the position of the current token.
+func (p *parser) errorf(format string, a ...interface{}) error {
+	t := p.peek()
+	found := t.val
+	if t.typ == tokEOF {
+		found = "end of script"
+	}
+	return fmt.Errorf("pos %d: %s but found '%s'", t.pos, fmt.Sprintf(format, a...), found)
+}
+
+// nest checks the nesting limit; call the returned function when leaving the level.
+func (p *parser) nest() (func(), error) {
+	p.depth++
+	if p.depth > MaxNesting {
+		return nil, fmt.Errorf("pos %d: nesting too deep (max. %d)", p.peek().pos, MaxNesting)
+	}
+	return func() { p.depth-- }, nil
+}
+
+// statement := if | while | return | assign | call
+func (p *parser) statement() (stmt, error) {
+	switch {
+	case p.is("if"):
+		return p.ifStatement()
+
+	case p.is("while"):
+		p.next()
+		cond, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &whileStmt{cond: cond, body: body}, nil
+
+	case p.is("return"):
+		p.next()
+		return &returnStmt{}, p.expect(";")
+
+	case p.peek().typ == tokIdent && p.tokens[p.pos+1].val == "=":
+		name := p.next().val
+		p.next() // =
+		if isKeyword(name) {
+			return nil, fmt.Errorf("'%s' is a keyword", name)
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return &assignStmt{name: name, value: value}, p.expect(";")
+
+	default:
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := value.(*callExpr); !ok {
+			return nil, fmt.Errorf("pos %d: expression without effect", p.peek().pos)
+		}
+		return &exprStmt{value: value}, p.expect(";")
+	}
+}
+
+// ifStatement := 'if' expr block ('else' (block | ifStatement))?
+func (p *parser) ifStatement() (stmt, error) {
+	p.next() // if
+	cond, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	then, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+
+	var els []stmt
+	if p.is("else") {
+		p.next()
+		if p.is("if") {
+			leave, err := p.nest()
+			if err != nil {
+				return nil, err
+			}
+			s, err := p.ifStatement()
+			leave()
+			if err != nil {
+				return nil, err
+			}
+			els = []stmt{s}
+		} else if els, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	return &ifStmt{cond: cond, then: then, els: els}, nil
+}
+
+// block := '{' statement* '}'
+func (p *parser) block() ([]stmt, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	list := make([]stmt, 0, 4)
+	for !p.is("}") {
+		if p.peek().typ == tokEOF {
+			return nil, p.errorf("expected '}'")
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	p.next() // }
+	return list, nil
+}
+
+// binary operators by precedence (lowest first)
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// expression parses a binary expression.
+func (p *parser) expression() (expr, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	return p.binary(0)
+}
+
+// binary parses all operators of the given precedence level.
+func (p *parser) binary(level int) (expr, error) {
+	if level >= len(precedence) {
+		return p.unary()
+	}
+
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range precedence[level] {
+			if p.peek().typ == tokOp && p.peek().val == o {
+				op = o
+			}
+		}
+		if op == "" {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: op, x: x, y: y}
+	}
+}
+
+// unary := ('-' | '!') unary | primary
+func (p *parser) unary() (expr, error) {
+	if p.is("-") || p.is("!") {
+		op := p.next().val
+		leave, err := p.nest()
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: op, x: x}, nil
+	}
+	return p.primary()
+}
+
+// primary := INT | IDENT | IDENT '(' args ')' | '(' expr ')'
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch {
+	case t.typ == tokInt:
+		p.next()
+		return &intExpr{value: t.num}, nil
+
+	case t.typ == tokIdent && !isKeyword(t.val):
+		p.next()
+		if !p.is("(") {
+			return &varExpr{name: t.val}, nil
+		}
+
+		// function call
+		p.next() // (
+		args := make([]expr, 0, 2)
+		for !p.is(")") {
+			if len(args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			a, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+		}
+		p.next() // )
+
+		// check function
+		b, ok := builtins[t.val]
+		if !ok {
+			return nil, fmt.Errorf("pos %d: unknown function '%s'", t.pos, t.val)
+		}
+		if len(args) != b.arity {
+			return nil, fmt.Errorf("pos %d: '%s' expects %d args but got %d", t.pos, t.val, b.arity, len(args))
+		}
+		return &callExpr{name: t.val, args: args}, nil
+
+	case p.is("("):
+		p.next()
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+
+	default:
+		return nil, p.errorf("expected expression")
+	}
+}
+
+// isKeyword returns true for reserved words.
+func isKeyword(name string) bool {
+	switch name {
+	case "if", "else", "while", "return":
+		return true
+	default:
+		return false
+	}
+}
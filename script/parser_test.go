@@ -0,0 +1,42 @@
+package script
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	p, err := Compile("test", `
+		# comment
+		a = 1 + 2 * 3;
+		if a >= 7 && !blocked() { forward(); } else if a < 0 { stop(); } else { left(); }
+		while a > 0 { a = a - 1; }
+		return;
+	`)
+	if err != nil || p.Name != "test" || len(p.body) != 4 {
+		t.Error("wrong value", p, err)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{`a = 1`, "pos 5: expected ';' but found 'end of script'"},
+		{`a = $;`, "pos 4: unexpected character '$'"},
+		{`nothing();`, "unknown function 'nothing'"},
+		{`fire(1);`, "'fire' expects 2 args but got 1"},
+		{`health;`, "expression without effect"},
+		{`if = 3;`, "pos 3"},
+		{`a = (1 + 2;`, "expected ')'"},
+		{`while 1 { `, "end of script"},
+		{strings.Repeat("if 1 { ", MaxNesting+1), "nesting too deep"},
+		{strings.Repeat(" ", MaxLength+1), "script too long"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile("test", tt.src); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Error("wrong value", tt.src, err)
+		}
+	}
+}